@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// nestedSeparator separates the path of a nested archive from the path inside it
+const nestedSeparator = "!/"
+
+// isNestedArchive reports whether an entry is opened as archive in recursive mode
+func isNestedArchive(name string) bool {
+	return detectArchiveFormat(name) != formatUnknown
+}
+
+// entryCollector gathers the file information of all entries of an archive,
+// including the entries of nested archives in recursive mode
+type entryCollector struct {
+	archiveName string
+	filter      *entryFilter
+	files       map[string]FileInfo
+	warnings    []string
+	ignored     []string // Normalized paths of entries skipped by the filter
+	comment     string   // Comment of the outer archive
+	count       int      // Number of entries added, gives FileInfo.Index
+
+	// Top level entries known to be equal in both archives, read without decompressing
+	unchanged map[string]bool
+
+	// Entries whose text content is read into contents, see readArchive
+	wanted   map[string]string // Key to the name of the entry compared under it
+	contents map[string]string
+}
+
+// newEntryCollector creates a collector, archiveName is used in warnings
+func newEntryCollector(archiveName string, filter *entryFilter) *entryCollector {
+	return &entryCollector{
+		archiveName: archiveName,
+		filter:      filter,
+		files:       make(map[string]FileInfo),
+	}
+}
+
+// add stores an entry under its BaseName. If the key is taken,
+// the entry without commit code is kept and a warning is recorded.
+func (c *entryCollector) add(fileInfo FileInfo) {
+	fileInfo.Index = c.count
+	c.count++
+
+	key := fileInfo.BaseName
+	existingFile, exists := c.files[key]
+	if !exists {
+		c.files[key] = fileInfo
+		return
+	}
+
+	// If we have a duplicate key, prefer the one without commit code
+	kept, dropped := existingFile.Name, fileInfo.Name
+	if len(existingFile.Name) > len(fileInfo.Name) {
+		c.files[key] = fileInfo
+		kept, dropped = dropped, kept
+	}
+	c.warnings = append(c.warnings, tr("warnCollision", c.archiveName, kept, dropped, key, kept))
+}
+
+// readArchive collects all entries of an archive. namePrefix and keyPrefix hold
+// the path of the enclosing archive entry for nested archives, depth their level.
+// Entries are hashed while streaming, only nested archives are read into memory.
+// If wanted is set, the text content of the wanted entries is read into contents instead.
+func (c *entryCollector) readArchive(reader archiveReader, namePrefix, keyPrefix string, depth int) error {
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if namePrefix != "" {
+				return fmt.Errorf("in %s: %w", strings.TrimSuffix(namePrefix, nestedSeparator), err)
+			}
+			return err
+		}
+
+		name := namePrefix + entry.Name
+		key := keyPrefix + normalizeEntryPath(entry.Name)
+		nested := opts.Recursive && depth < opts.MaxDepth && isNestedArchive(entry.Name)
+
+		// Filtered entries are skipped unread, include patterns only apply to files
+		if c.filter.excludes(name, key) || !nested && !c.filter.includes(name, key) {
+			c.ignored = append(c.ignored, key)
+			continue
+		}
+
+		// Entries with equal CRC32 and size in both archives are not decompressed.
+		// Entries with line filters are, so they are listed as normalized with or without --strict.
+		if !nested && depth == 0 && entry.HasCRC && c.unchanged[key] && len(lineFilterPatterns(name, key)) == 0 {
+			c.add(checksumFileInfo(name, key, entry))
+			continue
+		}
+
+		// Nested archives are replaced by their entries
+		if nested {
+			if _, ok := c.wanted[key]; c.wanted != nil && !ok && !c.wantsBelow(key) {
+				continue
+			}
+
+			content, err := io.ReadAll(entry.Content)
+			if err != nil {
+				return fmt.Errorf("failed to read file %s: %w", name, err)
+			}
+
+			nestedReader, err := newArchiveReaderFromBytes(entry.Name, content)
+			if err == nil {
+				err = c.readArchive(nestedReader, name+nestedSeparator, key+nestedSeparator, depth+1)
+				nestedReader.Close()
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			c.warnings = append(c.warnings, tr("warnNotArchive", c.archiveName, name))
+			if !c.filter.includes(name, key) {
+				c.ignored = append(c.ignored, key)
+				continue
+			}
+			entry.Content = bytes.NewReader(content)
+		}
+
+		if c.wanted != nil {
+			if wantedName, ok := c.wanted[key]; ok && wantedName == name {
+				text, err := readTextContent(name, key, entry.Content)
+				if err != nil {
+					return err
+				}
+				c.contents[key] = text
+			}
+			continue
+		}
+
+		fileInfo, err := hashEntry(name, key, entry.Content)
+		if err != nil {
+			return err
+		}
+		fileInfo.Metadata = entry.Metadata
+		c.add(fileInfo)
+	}
+}
+
+// wantsBelow reports whether a wanted entry lies inside the nested archive key
+func (c *entryCollector) wantsBelow(key string) bool {
+	for wanted := range c.wanted {
+		if strings.HasPrefix(wanted, key+nestedSeparator) {
+			return true
+		}
+	}
+	return false
+}
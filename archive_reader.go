@@ -0,0 +1,351 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveFormat identifies the container format of an archive
+type archiveFormat int
+
+const (
+	formatUnknown archiveFormat = iota
+	formatZip
+	formatTar
+	formatTarGz
+	formatTarBz2
+)
+
+// archiveSuffixes maps file name suffixes to archive formats, longer suffixes first
+var archiveSuffixes = []struct {
+	suffix string
+	format archiveFormat
+}{
+	{".tar.gz", formatTarGz},
+	{".tar.bz2", formatTarBz2},
+	{".tgz", formatTarGz},
+	{".tbz2", formatTarBz2},
+	{".tbz", formatTarBz2},
+	{".tar", formatTar},
+	{".zip", formatZip},
+	{".jar", formatZip},
+	{".war", formatZip},
+	{".ear", formatZip},
+	{".apk", formatZip},
+}
+
+// detectArchiveFormat determines the archive format from a file name
+func detectArchiveFormat(name string) archiveFormat {
+	lower := strings.ToLower(name)
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format
+		}
+	}
+	return formatUnknown
+}
+
+// archiveSuffix returns the archive suffix of a file name as written, or "" if there is none
+func archiveSuffix(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return name[len(name)-len(s.suffix):]
+		}
+	}
+	return ""
+}
+
+// sniffArchiveFormat determines the archive format from the first bytes of the content
+func sniffArchiveFormat(header []byte) archiveFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")) || bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return formatZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return formatTarGz
+	case bytes.HasPrefix(header, []byte("BZh")):
+		return formatTarBz2
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return formatTar
+	}
+	return formatUnknown
+}
+
+// archiveEntry is a regular file returned by an archiveReader
+type archiveEntry struct {
+	Name     string            // Path inside the archive
+	Content  io.Reader         // Only valid until the next call of Next
+	Metadata map[string]string // Stored attributes like "modified" and "mode", see --metadata
+
+	HasCRC bool   // CRC32 and Size are known from the ZIP central directory
+	CRC32  uint32 // CRC32 of the uncompressed content
+	Size   int64  // Uncompressed size
+}
+
+// archiveReader iterates over the regular files of an archive in archive order
+type archiveReader interface {
+	// Next returns the next regular file, or io.EOF after the last one
+	Next() (*archiveEntry, error)
+	// Comment returns the archive comment, empty for formats without one
+	Comment() string
+	Close() error
+}
+
+// openArchive opens a ZIP or tar archive, or a directory tree read like an archive.
+// The format is taken from the file extension; files with unknown extension are
+// recognized by their content and treated as ZIP if that fails, too.
+func openArchive(archivePath string) (archiveReader, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		file.Close()
+		return openDirectory(archivePath)
+	}
+
+	format := detectArchiveFormat(archivePath)
+	if format == formatUnknown {
+		header := make([]byte, 512)
+		n, _ := file.ReadAt(header, 0)
+		format = sniffArchiveFormat(header[:n])
+	}
+	if format == formatUnknown {
+		format = formatZip
+	}
+
+	reader, err := newArchiveReader(format, file, stat.Size(), file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// newArchiveReaderFromBytes opens an archive held in memory, e.g. a nested archive
+func newArchiveReaderFromBytes(name string, data []byte) (archiveReader, error) {
+	return newArchiveReader(detectArchiveFormat(name), bytes.NewReader(data), int64(len(data)), nil)
+}
+
+// newArchiveReader creates the reader for the given format. closer, if not nil,
+// is closed together with the archive reader.
+func newArchiveReader(format archiveFormat, source io.ReaderAt, size int64, closer io.Closer) (archiveReader, error) {
+	section := io.NewSectionReader(source, 0, size)
+
+	switch format {
+	case formatZip:
+		reader, err := zip.NewReader(source, size)
+		if err != nil {
+			return nil, err
+		}
+		return &zipArchiveReader{files: reader.File, comment: reader.Comment, closer: closer}, nil
+	case formatTar:
+		return &tarArchiveReader{reader: tar.NewReader(section), closers: []io.Closer{closer}}, nil
+	case formatTarGz:
+		gzipReader, err := gzip.NewReader(section)
+		if err != nil {
+			return nil, err
+		}
+		return &tarArchiveReader{reader: tar.NewReader(gzipReader), closers: []io.Closer{gzipReader, closer}}, nil
+	case formatTarBz2:
+		return &tarArchiveReader{reader: tar.NewReader(bzip2.NewReader(section)), closers: []io.Closer{closer}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown archive format")
+}
+
+// zipArchiveReader reads the entries of a ZIP archive
+type zipArchiveReader struct {
+	files   []*zip.File
+	comment string
+	next    int
+	current io.ReadCloser
+	closer  io.Closer
+}
+
+func (r *zipArchiveReader) Next() (*archiveEntry, error) {
+	r.closeCurrent()
+
+	for r.next < len(r.files) {
+		file := r.files[r.next]
+		r.next++
+
+		// Skip directories
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		fileReader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file %s in ZIP: %w", file.Name, err)
+		}
+		r.current = fileReader
+
+		return &archiveEntry{
+			Name:     file.Name,
+			Content:  fileReader,
+			Metadata: zipMetadata(&file.FileHeader),
+			HasCRC:   true,
+			CRC32:    file.CRC32,
+			Size:     int64(file.UncompressedSize64),
+		}, nil
+	}
+
+	return nil, io.EOF
+}
+
+func (r *zipArchiveReader) Comment() string {
+	return r.comment
+}
+
+func (r *zipArchiveReader) closeCurrent() {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
+
+func (r *zipArchiveReader) Close() error {
+	r.closeCurrent()
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// tarArchiveReader reads the entries of a (compressed) tar archive
+type tarArchiveReader struct {
+	reader  *tar.Reader
+	closers []io.Closer
+}
+
+func (r *tarArchiveReader) Next() (*archiveEntry, error) {
+	for {
+		header, err := r.reader.Next()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		// Only regular files have content, directories and links are skipped
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		metadata := map[string]string{
+			"modified": formatModTime(header.ModTime),
+			"mode":     header.FileInfo().Mode().String(),
+		}
+		return &archiveEntry{Name: header.Name, Content: r.reader, Metadata: metadata}, nil
+	}
+}
+
+func (r *tarArchiveReader) Comment() string {
+	return ""
+}
+
+func (r *tarArchiveReader) Close() error {
+	var firstErr error
+	for _, closer := range r.closers {
+		if closer == nil {
+			continue
+		}
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// directoryReader reads the regular files of an unpacked directory tree like an archive
+type directoryReader struct {
+	root    string
+	files   []string // Slash separated paths relative to root, in lexical order
+	next    int
+	current *os.File
+}
+
+// openDirectory lists the regular files below root. Symbolic links and
+// other special files are skipped like in tar archives.
+func openDirectory(root string) (archiveReader, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &directoryReader{root: root, files: files}, nil
+}
+
+func (r *directoryReader) Next() (*archiveEntry, error) {
+	r.closeCurrent()
+
+	if r.next >= len(r.files) {
+		return nil, io.EOF
+	}
+	name := r.files[r.next]
+	r.next++
+
+	file, err := os.Open(filepath.Join(r.root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", name, err)
+	}
+	r.current = file
+
+	var metadata map[string]string
+	if info, err := file.Stat(); err == nil {
+		metadata = map[string]string{
+			"modified": formatModTime(info.ModTime()),
+			"mode":     info.Mode().String(),
+		}
+	}
+
+	return &archiveEntry{Name: name, Content: file, Metadata: metadata}, nil
+}
+
+func (r *directoryReader) Comment() string {
+	return ""
+}
+
+func (r *directoryReader) closeCurrent() {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
+
+func (r *directoryReader) Close() error {
+	r.closeCurrent()
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// createTestTarIn writes a tar archive with the given files to dir/name,
+// gzip compressed if the name ends in .tar.gz or .tgz
+func createTestTarIn(t *testing.T, dir, name string, files map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+
+	// A directory entry that must be skipped
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatalf("Failed to write directory header: %v", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for filename := range files {
+		names = append(names, filename)
+	}
+	sort.Strings(names)
+	for _, filename := range names {
+		content := files[filename]
+		header := &tar.Header{Name: "./" + filename, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write header for %s: %v", filename, err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+
+	data := buf.Bytes()
+	if format := detectArchiveFormat(name); format == formatTarGz {
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		gzipWriter.Write(data)
+		gzipWriter.Close()
+		data = compressed.Bytes()
+	}
+
+	tarPath := filepath.Join(dir, name)
+	if err := os.WriteFile(tarPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write tar: %v", err)
+	}
+	return tarPath
+}
+
+func TestDetectArchiveFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected archiveFormat
+	}{
+		{"a.zip", formatZip},
+		{"a.JAR", formatZip},
+		{"a.tar", formatTar},
+		{"a.tar.gz", formatTarGz},
+		{"a.tgz", formatTarGz},
+		{"a.tar.bz2", formatTarBz2},
+		{"a.tbz2", formatTarBz2},
+		{"a.gz", formatUnknown},
+		{"a.txt", formatUnknown},
+	}
+
+	for _, test := range tests {
+		if got := detectArchiveFormat(test.name); got != test.expected {
+			t.Errorf("detectArchiveFormat(%q) = %d, expected %d", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestReadTarContents(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"file.txt": "content", "sub/other.txt": "other"}
+
+	for _, name := range []string{"plain.tar", "compressed.tar.gz", "short.tgz"} {
+		tarPath := createTestTarIn(t, dir, name, files)
+
+		collector, err := readZipContents(tarPath, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: readZipContents failed: %v", name, err)
+		}
+		contents := collector.files
+		if len(contents) != 2 {
+			t.Errorf("%s: expected 2 files, got %d", name, len(contents))
+		}
+		if other := contents["sub/other.txt"]; other.Size != 5 || other.IsBinary {
+			t.Errorf("%s: unexpected file info for sub/other.txt: %+v", name, other)
+		}
+	}
+}
+
+func TestCompareZipWithTarGz(t *testing.T) {
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "release.zip", map[string]string{"file.txt": "content", "changed.txt": "old"})
+	tarPath := createTestTarIn(t, dir, "release.tar.gz", map[string]string{"file.txt": "content", "changed.txt": "new"})
+
+	result, err := compareZipFiles(filepath.Join(dir, "release.zip"), tarPath)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+
+	if len(result.Identical) != 1 || result.Identical[0] != "file.txt" {
+		t.Errorf("Expected file.txt to be identical, got %v", result.Identical)
+	}
+	if len(result.Different) != 1 || result.Different[0] != "changed.txt" {
+		t.Errorf("Expected changed.txt to differ, got %v", result.Different)
+	}
+}
+
+func TestOpenArchiveSniffsFormat(t *testing.T) {
+	dir := t.TempDir()
+	tarPath := createTestTarIn(t, dir, "archive.tar.gz", map[string]string{"file.txt": "content"})
+	renamed := filepath.Join(dir, "archive.bin")
+	if err := os.Rename(tarPath, renamed); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+
+	collector, err := readZipContents(renamed, nil, nil)
+	if err != nil {
+		t.Fatalf("readZipContents failed: %v", err)
+	}
+	if _, ok := collector.files["file.txt"]; !ok {
+		t.Errorf("Expected file.txt in sniffed archive, got %v", collector.files)
+	}
+}
+
+func TestFindZipPairsMixedFormats(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	createTestZipIn(t, dir1, "package_v1.zip", map[string]string{"file.txt": "content"})
+	createTestTarIn(t, dir2, "package_v2.tar.gz", map[string]string{"file.txt": "content"})
+	createTestTarIn(t, dir2, "tools_v2.tgz", map[string]string{"file.txt": "content"})
+
+	pairs, unmatched, err := findZipPairs(dir1, dir2)
+	if err != nil {
+		t.Fatalf("findZipPairs failed: %v", err)
+	}
+
+	if len(pairs) != 1 || pairs[0].BaseName != "package" {
+		t.Fatalf("Expected pair package, got %v", pairs)
+	}
+	if len(unmatched.OnlyInDir2) != 1 || filepath.Base(unmatched.OnlyInDir2[0]) != "tools_v2.tgz" {
+		t.Errorf("Expected tools_v2.tgz unmatched, got %v", unmatched.OnlyInDir2)
+	}
+}
+
+// writeTestTree creates the given files below a new temporary directory
+func writeTestTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func TestCompareZipWithDirectoryTree(t *testing.T) {
+	files := map[string]string{"file_abc123.txt": "content", "sub/other.txt": "other"}
+	zipPath, err := createTestZip(files)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	defer os.Remove(zipPath)
+
+	tree := writeTestTree(t, map[string]string{"file_def456.txt": "content", "sub/other.txt": "changed"})
+
+	result, err := compareZipFiles(zipPath, tree)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+
+	if len(result.Identical) != 1 || result.Identical[0] != "file.txt" {
+		t.Errorf("Expected file.txt to be identical, got %v", result.Identical)
+	}
+	if len(result.Different) != 1 || result.Different[0] != "sub/other.txt" {
+		t.Errorf("Expected sub/other.txt to differ, got %v", result.Different)
+	}
+}
+
+func TestRunTreeMode(t *testing.T) {
+	tree1 := writeTestTree(t, map[string]string{"a/file.txt": "content"})
+	tree2 := writeTestTree(t, map[string]string{"a/file.txt": "content"})
+	tree3 := writeTestTree(t, map[string]string{"a/file.txt": "changed"})
+	zipPath, err := createTestZip(map[string]string{"a/file.txt": "content"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	defer os.Remove(zipPath)
+
+	if code := runQuiet(t, "--tree", tree1, tree2); code != exitIdentical {
+		t.Errorf("Identical trees: expected exit code %d, got %d", exitIdentical, code)
+	}
+	if code := runQuiet(t, "--tree", tree1, tree3); code != exitDifferences {
+		t.Errorf("Different trees: expected exit code %d, got %d", exitDifferences, code)
+	}
+	if code := runQuiet(t, "--tree", zipPath, tree1); code != exitIdentical {
+		t.Errorf("ZIP against tree: expected exit code %d, got %d", exitIdentical, code)
+	}
+	if code := runQuiet(t, zipPath, tree1); code != exitError {
+		t.Errorf("ZIP against directory without --tree: expected exit code %d, got %d", exitError, code)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+)
+
+// zipBytes returns the bytes of a ZIP archive with the given files
+func zipBytes(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for filename, content := range files {
+		writer, err := zipWriter.Create(filename)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", filename, err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+
+	return buf.String()
+}
+
+// nestedTestZips creates two ZIP files containing app.war with an inner x.jar,
+// the jars differ in com/Foo.class
+func nestedTestZips(t *testing.T) (string, string) {
+	t.Helper()
+
+	war := func(classContent string) string {
+		jar := zipBytes(t, map[string]string{"com/Foo.class": classContent, "META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n"})
+		return zipBytes(t, map[string]string{"WEB-INF/lib/x.jar": jar, "index.html": "<html></html>"})
+	}
+
+	zip1, err := createTestZip(map[string]string{"app.war": war("class v1"), "readme.txt": "readme"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(zip1) })
+
+	zip2, err := createTestZip(map[string]string{"app.war": war("class v2"), "readme.txt": "readme"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(zip2) })
+
+	return zip1, zip2
+}
+
+func TestCompareNestedArchives(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	zip1, zip2 := nestedTestZips(t)
+
+	tests := []struct {
+		recursive bool
+		maxDepth  int
+		different string
+		identical int
+	}{
+		{false, 5, "app.war", 1},
+		{true, 1, "app.war!/WEB-INF/lib/x.jar", 2},
+		{true, 5, "app.war!/WEB-INF/lib/x.jar!/com/Foo.class", 3},
+	}
+
+	for _, test := range tests {
+		opts.Recursive = test.recursive
+		opts.MaxDepth = test.maxDepth
+
+		result, err := compareZipFiles(zip1, zip2)
+		if err != nil {
+			t.Fatalf("compareZipFiles failed: %v", err)
+		}
+
+		if len(result.Different) != 1 || result.Different[0] != test.different {
+			t.Errorf("recursive=%v maxDepth=%d: expected %s to differ, got %v",
+				test.recursive, test.maxDepth, test.different, result.Different)
+		}
+		if len(result.Identical) != test.identical {
+			t.Errorf("recursive=%v maxDepth=%d: expected %d identical files, got %v",
+				test.recursive, test.maxDepth, test.identical, result.Identical)
+		}
+	}
+}
+
+func TestNestedArchiveThatIsNotAZip(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.Recursive = true
+	result := compareTestZips(t, map[string]string{"lib.jar": "not a zip"}, map[string]string{"lib.jar": "not a zip"})
+
+	if len(result.Identical) != 1 || result.Identical[0] != "lib.jar" {
+		t.Errorf("Expected lib.jar to be compared as a file, got %v", result.Identical)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected a warning per archive, got %v", result.Warnings)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes of zipcompare, documented in the README
+const (
+	exitIdentical   = 0 // Everything compared is identical, or help was requested
+	exitDifferences = 1 // At least one difference was found
+	exitError       = 2 // Invalid usage or a comparison could not be completed
+)
+
+// console receives all regular output, --quiet replaces it with io.Discard.
+// See setupConsole for --plain and --color.
+var console io.Writer = os.Stdout
+
+// exitStatus maps the outcome of a successful comparison to an exit code
+func exitStatus(differences bool) int {
+	if differences {
+		return exitDifferences
+	}
+	return exitIdentical
+}
+
+// printUsage prints the command line help, including the flags of fs if given
+func printUsage(fs *flag.FlagSet) {
+	fmt.Println(tr("usage"))
+	if fs != nil {
+		fmt.Println()
+		fmt.Println(tr("usageOptions"))
+		fs.SetOutput(os.Stdout)
+		fs.PrintDefaults()
+	}
+}
+
+// errUsage is returned by parseCommandLine when the flags are invalid,
+// the usage has already been printed then. Requested help returns flag.ErrHelp.
+var errUsage = errors.New("invalid usage")
+
+// parseCommandLine applies the config file and the command line flags to opts
+// and returns the remaining positional arguments. Flags may appear before,
+// between or after the positional arguments and override config file settings.
+func parseCommandLine(args []string) ([]string, error) {
+	var configPath string
+
+	// Usage and flag errors are printed before compile, in the language of the environment
+	opts.lang = environmentLanguage()
+
+	fs := flag.NewFlagSet("zipcompare", flag.ContinueOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.StringVar(&configPath, "config", "", "JSON config file with default settings")
+	fs.StringVar(&opts.OutputPath, "o", opts.OutputPath, "write the report to this file (directory mode: directory)")
+	fs.StringVar(&opts.OutputPath, "output", opts.OutputPath, "same as -o")
+	fs.StringVar(&opts.Format, "format", opts.Format, "report format: "+strings.Join(reportFormatNames(), ", "))
+	fs.BoolVar(&opts.Quiet, "q", opts.Quiet, "no console output, only the exit code and errors")
+	fs.BoolVar(&opts.Quiet, "quiet", opts.Quiet, "same as -q")
+	fs.BoolVar(&opts.Plain, "plain", opts.Plain, "console output without emojis and colors, e.g. for CI logs")
+	fs.StringVar(&opts.Color, "color", opts.Color, "color console output: auto (terminal only, honors NO_COLOR), always or never")
+	fs.StringVar(&opts.Order, "order", opts.Order, "order of files in output and reports: path or archive")
+	fs.BoolVar(&opts.NoTimestamp, "no-timestamp", opts.NoTimestamp, "leave the generation time out of reports for byte-reproducible output")
+	fs.IntVar(&opts.Jobs, "jobs", opts.Jobs, "number of ZIP pairs compared in parallel in directory mode, 0 for one per CPU")
+	fs.StringVar(&opts.Lang, "lang", opts.Lang, "language of console output and HTML reports: en or de (default from LC_ALL/LANG)")
+	fs.IntVar(&opts.ContextLines, "context", opts.ContextLines, "number of unchanged context lines around each diff hunk")
+	fs.BoolVar(&opts.ShowDiff, "show-diff", opts.ShowDiff, "print the unified diff of each different file to the console")
+	fs.IntVar(&opts.DiffLines, "diff-lines", opts.DiffLines, "maximum diff lines printed per file with --show-diff, 0 for all")
+	fs.StringVar(&opts.Intraline, "intraline", opts.Intraline, "highlight changes within lines: word, char or none")
+	fs.StringVar(&opts.CommitPattern, "commit-pattern", opts.CommitPattern,
+		"regex matching names with commit codes, with a (?P<name>...) and optional (?P<ext>...) group")
+	fs.BoolVar(&opts.NoNormalize, "no-normalize", opts.NoNormalize, "compare names exactly as stored, without removing commit codes")
+	fs.BoolVar(&opts.NoRenames, "no-renames", opts.NoRenames, "do not detect renamed and moved files")
+	fs.Float64Var(&opts.RenameThreshold, "rename-threshold", opts.RenameThreshold,
+		"also pair text files with at least this line similarity (0-1) as renamed, 0 pairs identical content only")
+	fs.Var((*stringList)(&opts.Include), "include", "only compare files matching this glob, e.g. **/*.class (repeatable)")
+	fs.Var((*stringList)(&opts.Exclude), "exclude", "ignore entries matching this glob, e.g. *.log (repeatable)")
+	fs.Var((*stringList)(&opts.IgnoreLines), "ignore-lines", "remove lines matching this regex from all text files before comparing (repeatable)")
+	fs.BoolVar(&opts.IgnoreEOL, "ignore-eol", opts.IgnoreEOL, "treat CRLF and LF line endings as equal")
+	fs.BoolVar(&opts.IgnoreBOM, "ignore-bom", opts.IgnoreBOM, "ignore a UTF-8 byte order mark at the start of text files")
+	fs.BoolVar(&opts.IgnoreTrailingSpace, "ignore-trailing-space", opts.IgnoreTrailingSpace, "ignore spaces and tabs at the end of lines")
+	fs.BoolVar(&opts.IgnoreAllSpace, "ignore-all-space", opts.IgnoreAllSpace, "ignore all whitespace within lines")
+	fs.BoolVar(&opts.IgnoreCase, "ignore-case", opts.IgnoreCase, "compare text case-insensitively")
+	fs.Int64Var(&opts.MaxDiffSize, "max-diff-size", opts.MaxDiffSize,
+		"largest file in bytes that is read into memory for a diff, 0 for no limit")
+	fs.BoolVar(&opts.Strict, "strict", opts.Strict,
+		"hash every entry instead of trusting equal CRC32 and size in the ZIP central directories")
+	fs.BoolVar(&opts.CompareMetadata, "metadata", opts.CompareMetadata,
+		"also compare modification times, permissions, compression method, comments and extra fields")
+	fs.BoolVar(&opts.Tree, "tree", opts.Tree, "read directory arguments as unpacked trees instead of folders of ZIP files")
+	fs.BoolVar(&opts.Recursive, "recursive", opts.Recursive, "compare the entries of nested archives (zip, jar, war, ear, apk, tar, tar.gz, tar.bz2)")
+	fs.IntVar(&opts.MaxDepth, "max-depth", opts.MaxDepth, "maximum nesting level of archives opened with --recursive")
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, flag.ErrHelp
+	}
+	if err != nil {
+		return nil, errUsage
+	}
+
+	if configPath != "" {
+		if err := loadConfig(configPath, &opts); err != nil {
+			return nil, err
+		}
+		// Parse again so explicit flags win over the config file
+		if positional, err = parseInterspersed(fs, args); err != nil {
+			return nil, errUsage
+		}
+	}
+
+	if err := opts.compile(); err != nil {
+		return nil, err
+	}
+
+	setupConsole(os.Stdout)
+
+	return positional, nil
+}
+
+// stringList is a repeatable flag collecting its values
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ", ")
+}
+
+// Set appends a value, values already present are skipped as flags are parsed twice with --config
+func (l *stringList) Set(value string) error {
+	for _, existing := range *l {
+		if existing == value {
+			return nil
+		}
+	}
+	*l = append(*l, value)
+	return nil
+}
+
+// parseInterspersed parses flags that may be mixed with positional arguments.
+// Everything after a "--" argument is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// runQuiet runs zipcompare with --quiet and restores the global options afterwards
+func runQuiet(t *testing.T, args ...string) int {
+	t.Helper()
+
+	savedOpts, savedConsole := opts, console
+	defer func() { opts, console = savedOpts, savedConsole }()
+
+	opts = defaultOptions()
+	return run(append([]string{"--quiet"}, args...))
+}
+
+func TestRunExitCodes(t *testing.T) {
+	zip1, err := createTestZip(map[string]string{"file.txt": "content"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	defer os.Remove(zip1)
+
+	zip2, err := createTestZip(map[string]string{"file.txt": "content"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	zip3, err := createTestZip(map[string]string{"file.txt": "changed"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 3: %v", err)
+	}
+	defer os.Remove(zip3)
+
+	if code := runQuiet(t, zip1, zip2); code != exitIdentical {
+		t.Errorf("identical ZIPs: exit code %d; want %d", code, exitIdentical)
+	}
+
+	if code := runQuiet(t, zip1, zip3); code != exitDifferences {
+		t.Errorf("different ZIPs: exit code %d; want %d", code, exitDifferences)
+	}
+
+	if code := runQuiet(t, zip1, filepath.Join(t.TempDir(), "missing.zip")); code != exitError {
+		t.Errorf("missing ZIP: exit code %d; want %d", code, exitError)
+	}
+
+	if code := runQuiet(t, "--format", "yaml", zip1, zip2); code != exitError {
+		t.Errorf("unknown format: exit code %d; want %d", code, exitError)
+	}
+
+	// Flags after the positional arguments are accepted too
+	report := filepath.Join(t.TempDir(), "report.xml")
+	if code := runQuiet(t, zip1, zip3, "-o", report); code != exitDifferences {
+		t.Errorf("different ZIPs with -o: exit code %d; want %d", code, exitDifferences)
+	}
+	if _, err := os.Stat(report); err != nil {
+		t.Errorf("Expected report at %s: %v", report, err)
+	}
+}
+
+func TestRunDirectoryExitCodes(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	dir3 := t.TempDir()
+
+	createTestZipIn(t, dir1, "package_v1.zip", map[string]string{"file.txt": "content"})
+	createTestZipIn(t, dir2, "package_v2.zip", map[string]string{"file.txt": "content"})
+	createTestZipIn(t, dir3, "package_v3.zip", map[string]string{"file.txt": "changed"})
+
+	if code := runQuiet(t, dir1, dir2); code != exitIdentical {
+		t.Errorf("identical directories: exit code %d; want %d", code, exitIdentical)
+	}
+
+	if code := runQuiet(t, dir1, dir3); code != exitDifferences {
+		t.Errorf("different directories: exit code %d; want %d", code, exitDifferences)
+	}
+
+	// A ZIP without partner is a difference
+	createTestZipIn(t, dir1, "extra_v1.zip", map[string]string{"file.txt": "content"})
+	if code := runQuiet(t, dir1, dir2); code != exitDifferences {
+		t.Errorf("unmatched ZIP: exit code %d; want %d", code, exitDifferences)
+	}
+
+	// A broken archive is an error, not a difference
+	if err := os.WriteFile(filepath.Join(dir3, "broken_v1.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write broken ZIP: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir1, "broken_v2.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write broken ZIP: %v", err)
+	}
+	if code := runQuiet(t, dir1, dir3); code != exitError {
+		t.Errorf("broken ZIP pair: exit code %d; want %d", code, exitError)
+	}
+}
+
+func TestRunHelpExitCode(t *testing.T) {
+	// The usage goes to stdout, keep it out of the test output
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	savedStdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = savedStdout }()
+
+	for _, arg := range []string{"--help", "-h"} {
+		if code := runQuiet(t, arg); code != exitIdentical {
+			t.Errorf("%s: exit code %d; want %d", arg, code, exitIdentical)
+		}
+	}
+	if code := runQuiet(t, "--no-such-flag"); code != exitError {
+		t.Errorf("unknown flag: exit code %d; want %d", code, exitError)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// configFile mirrors Options for the JSON configuration file.
+// Pointer fields distinguish absent settings from explicit zero values.
+type configFile struct {
+	ContextLines        *int         `json:"contextLines"`
+	ShowDiff            *bool        `json:"showDiff"`
+	DiffLines           *int         `json:"diffLines"`
+	Intraline           *string      `json:"intraline"`
+	CommitPattern       *string      `json:"commitPattern"`
+	NormalizeNames      *bool        `json:"normalizeNames"`
+	Format              *string      `json:"format"`
+	Plain               *bool        `json:"plain"`
+	Color               *string      `json:"color"`
+	DetectRenames       *bool        `json:"detectRenames"`
+	RenameThreshold     *float64     `json:"renameThreshold"`
+	Include             []string     `json:"include"`
+	Exclude             []string     `json:"exclude"`
+	IgnoreLines         []string     `json:"ignoreLines"`
+	LineFilters         []LineFilter `json:"lineFilters"`
+	IgnoreEOL           *bool        `json:"ignoreEol"`
+	IgnoreBOM           *bool        `json:"ignoreBom"`
+	IgnoreTrailingSpace *bool        `json:"ignoreTrailingSpace"`
+	IgnoreAllSpace      *bool        `json:"ignoreAllSpace"`
+	IgnoreCase          *bool        `json:"ignoreCase"`
+	Order               *string      `json:"order"`
+	Timestamp           *bool        `json:"timestamp"`
+	Jobs                *int         `json:"jobs"`
+	Lang                *string      `json:"lang"`
+	MaxDiffSize         *int64       `json:"maxDiffSize"`
+	Strict              *bool        `json:"strict"`
+	CompareMetadata     *bool        `json:"compareMetadata"`
+	Tree                *bool        `json:"tree"`
+	Recursive           *bool        `json:"recursive"`
+	MaxDepth            *int         `json:"maxDepth"`
+}
+
+// loadConfig reads a JSON configuration file and applies its settings to o
+func loadConfig(configPath string, o *Options) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	var config configFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	if config.ContextLines != nil {
+		o.ContextLines = *config.ContextLines
+	}
+	if config.ShowDiff != nil {
+		o.ShowDiff = *config.ShowDiff
+	}
+	if config.DiffLines != nil {
+		o.DiffLines = *config.DiffLines
+	}
+	if config.Intraline != nil {
+		o.Intraline = *config.Intraline
+	}
+	if config.CommitPattern != nil {
+		o.CommitPattern = *config.CommitPattern
+	}
+	if config.NormalizeNames != nil {
+		o.NoNormalize = !*config.NormalizeNames
+	}
+
+	if config.Format != nil {
+		o.Format = *config.Format
+	}
+	if config.Plain != nil {
+		o.Plain = *config.Plain
+	}
+	if config.Color != nil {
+		o.Color = *config.Color
+	}
+	if config.DetectRenames != nil {
+		o.NoRenames = !*config.DetectRenames
+	}
+	if config.RenameThreshold != nil {
+		o.RenameThreshold = *config.RenameThreshold
+	}
+	if config.Include != nil {
+		o.Include = config.Include
+	}
+	if config.Exclude != nil {
+		o.Exclude = config.Exclude
+	}
+	if config.IgnoreLines != nil {
+		o.IgnoreLines = config.IgnoreLines
+	}
+	if config.LineFilters != nil {
+		o.LineFilters = config.LineFilters
+	}
+	if config.IgnoreEOL != nil {
+		o.IgnoreEOL = *config.IgnoreEOL
+	}
+	if config.IgnoreBOM != nil {
+		o.IgnoreBOM = *config.IgnoreBOM
+	}
+	if config.IgnoreTrailingSpace != nil {
+		o.IgnoreTrailingSpace = *config.IgnoreTrailingSpace
+	}
+	if config.IgnoreAllSpace != nil {
+		o.IgnoreAllSpace = *config.IgnoreAllSpace
+	}
+	if config.IgnoreCase != nil {
+		o.IgnoreCase = *config.IgnoreCase
+	}
+	if config.Order != nil {
+		o.Order = *config.Order
+	}
+	if config.Timestamp != nil {
+		o.NoTimestamp = !*config.Timestamp
+	}
+	if config.Jobs != nil {
+		o.Jobs = *config.Jobs
+	}
+	if config.Lang != nil {
+		o.Lang = *config.Lang
+	}
+	if config.MaxDiffSize != nil {
+		o.MaxDiffSize = *config.MaxDiffSize
+	}
+	if config.Strict != nil {
+		o.Strict = *config.Strict
+	}
+	if config.CompareMetadata != nil {
+		o.CompareMetadata = *config.CompareMetadata
+	}
+	if config.Tree != nil {
+		o.Tree = *config.Tree
+	}
+	if config.Recursive != nil {
+		o.Recursive = *config.Recursive
+	}
+	if config.MaxDepth != nil {
+		o.MaxDepth = *config.MaxDepth
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigFileAndFlagPrecedence(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	configPath := filepath.Join(t.TempDir(), "zipcompare.json")
+	config := `{"contextLines": 5, "commitPattern": "^(?P<name>.+)-g[0-9a-f]{7,}(?P<ext>\\.[^.]*)?$"}`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	opts = defaultOptions()
+	args, err := parseCommandLine([]string{"--config", configPath, "--context", "1", "a.zip", "b.zip"})
+	if err != nil {
+		t.Fatalf("parseCommandLine failed: %v", err)
+	}
+
+	if len(args) != 2 {
+		t.Errorf("Expected 2 positional arguments, got %v", args)
+	}
+	if opts.ContextLines != 1 {
+		t.Errorf("Expected --context to override the config file, got %d", opts.ContextLines)
+	}
+	if result := extractBaseName("app-g1a2b3c4.jar"); result != "app.jar" {
+		t.Errorf("Expected commit pattern from config file, extractBaseName returned %s", result)
+	}
+}
+
+func TestConfigFileRejectsUnknownSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "zipcompare.json")
+	if err := os.WriteFile(configPath, []byte(`{"contextLine": 5}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	o := defaultOptions()
+	if err := loadConfig(configPath, &o); err == nil {
+		t.Error("loadConfig should reject unknown settings")
+	}
+}
+
+func TestRepeatedPatternFlagsWithConfig(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	configPath := filepath.Join(t.TempDir(), "zipcompare.json")
+	if err := os.WriteFile(configPath, []byte(`{"exclude": ["*.log"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	opts = defaultOptions()
+	_, err := parseCommandLine([]string{"--config", configPath, "--exclude", "*.tmp", "--exclude", "build/", "a.zip", "b.zip"})
+	if err != nil {
+		t.Fatalf("parseCommandLine failed: %v", err)
+	}
+
+	expected := []string{"*.log", "*.tmp", "build/"}
+	if !reflect.DeepEqual(opts.Exclude, expected) {
+		t.Errorf("Expected excludes %v, got %v", expected, opts.Exclude)
+	}
+}
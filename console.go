@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Values of --color
+const (
+	colorAuto   = "auto"   // Color when writing to a terminal and NO_COLOR is not set
+	colorAlways = "always" // Color even when the output is redirected
+	colorNever  = "never"  // Never color
+)
+
+// ANSI escape sequences used for colored console output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+
+	ansiReverse   = "\x1b[7m"
+	ansiNoReverse = "\x1b[27m"
+)
+
+// colorOutput reports whether console output is colored, set together with console
+var colorOutput bool
+
+// plainReplacer replaces the emojis and typographic symbols of console output with ASCII for --plain
+var plainReplacer = strings.NewReplacer(
+	"✅ ", "",
+	"⚠️  ", "",
+	"⚙️  ", "",
+	"🏷️  ", "",
+	"📁 ", "",
+	"📋 ", "",
+	"📊 ", "",
+	"🔀 ", "",
+	"🧹 ", "",
+	"📄 ", "",
+	"📑 ", "",
+	"🔍 ", "",
+	"🎉 ", "",
+	"❌ ", "",
+	"❗ ", "! ",
+	"•", "-",
+	"→", "->",
+)
+
+// plain returns a console format string with its symbols replaced in --plain mode.
+// Only format strings are passed, so file names and diff lines are printed unchanged.
+func plain(format string) string {
+	if !opts.Plain {
+		return format
+	}
+	return plainReplacer.Replace(format)
+}
+
+// setupConsole selects the console writer for the options and decides whether it is colored
+func setupConsole(out *os.File) {
+	console, colorOutput = out, false
+	if opts.Quiet {
+		console = io.Discard
+		return
+	}
+
+	mode := opts.Color
+	if opts.Plain && mode == colorAuto {
+		mode = colorNever
+	}
+	colorOutput = colorEnabled(mode, out)
+}
+
+// colorEnabled decides whether output to out is colored in the given --color mode.
+// In auto mode, NO_COLOR, TERM=dumb and redirected output disable colors, as do
+// Windows consoles without support for ANSI escape sequences.
+func colorEnabled(mode string, out *os.File) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(out) && enableVirtualTerminal(out)
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps text in an ANSI color if console output is colored
+func paint(color, text string) string {
+	if !colorOutput {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// colorDiffLine colors a line of a unified diff by its prefix:
+// file headers bold, hunk headers cyan, deletions red and insertions green
+func colorDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ "):
+		return paint(ansiBold, line)
+	case strings.HasPrefix(line, "@@ "):
+		return paint(ansiCyan, line)
+	case strings.HasPrefix(line, "-"):
+		return paint(ansiRed, line)
+	case strings.HasPrefix(line, "+"):
+		return paint(ansiGreen, line)
+	}
+	return line
+}
+
+// colorDiffLines colors the lines of a unified diff starting with its two file
+// headers. Deleted lines are paired by position with the inserted lines following
+// them, and the changed parts of each pair are highlighted, see --intraline.
+func colorDiffLines(lines []string) []string {
+	if !colorOutput {
+		return lines
+	}
+
+	colored := make([]string, len(lines))
+	for i := 0; i < len(lines); {
+		switch {
+		case i < 2:
+			colored[i] = colorDiffLine(lines[i])
+			i++
+		case strings.HasPrefix(lines[i], "-"):
+			deletedEnd := i
+			for deletedEnd < len(lines) && strings.HasPrefix(lines[deletedEnd], "-") {
+				deletedEnd++
+			}
+			insertedEnd := deletedEnd
+			for insertedEnd < len(lines) && strings.HasPrefix(lines[insertedEnd], "+") {
+				insertedEnd++
+			}
+
+			deleted, inserted := lines[i:deletedEnd], lines[deletedEnd:insertedEnd]
+			for n := range deleted {
+				var oldSpans, newSpans []Span
+				if n < len(inserted) {
+					oldSpans, newSpans = intralineSpans(deleted[n][1:], inserted[n][1:])
+					colored[deletedEnd+n] = paint(ansiGreen, "+"+highlightSpans(inserted[n][1:], newSpans))
+				}
+				colored[i+n] = paint(ansiRed, "-"+highlightSpans(deleted[n][1:], oldSpans))
+			}
+			for n := len(deleted); n < len(inserted); n++ {
+				colored[deletedEnd+n] = paint(ansiGreen, inserted[n])
+			}
+			i = insertedEnd
+		case strings.HasPrefix(lines[i], "+"):
+			colored[i] = paint(ansiGreen, lines[i])
+			i++
+		default:
+			colored[i] = colorDiffLine(lines[i])
+			i++
+		}
+	}
+	return colored
+}
+
+// printDiffs writes the unified diffs of the different files to w for --show-diff.
+// With opts.DiffLines set, only that many lines after the file headers are shown per file.
+func printDiffs(w io.Writer, details []DiffInfo) {
+	for _, detail := range details {
+		switch {
+		case detail.IsBinary:
+			fmt.Fprintln(w, paint(ansiBold, tr("binaryDiffer", detail.FileName)))
+		case detail.DiffSkipped:
+			fmt.Fprintln(w, paint(ansiBold, tr("diffSkipped", detail.FileName)))
+		case detail.Diff == "":
+			// Files differing in a way the active modes ignore have no diff
+			continue
+		default:
+			lines := strings.Split(strings.TrimSuffix(detail.Diff, "\n"), "\n")
+			shown := len(lines)
+			if opts.DiffLines > 0 && len(lines) > opts.DiffLines+2 {
+				shown = opts.DiffLines + 2 // The --- and +++ headers are always shown
+			}
+			for _, line := range colorDiffLines(lines[:shown]) {
+				fmt.Fprintln(w, line)
+			}
+			if shown < len(lines) {
+				fmt.Fprintln(w, paint(ansiCyan, tr("diffTruncated", len(lines)-shown)))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
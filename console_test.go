@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlainConsoleOutput(t *testing.T) {
+	savedOpts, savedConsole, savedColor := opts, console, colorOutput
+	defer func() { opts, console, colorOutput = savedOpts, savedConsole, savedColor }()
+
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"same.txt": "a\n", "changed.txt": "old\n", "old.txt": "x\n"})
+	createTestZipIn(t, dir, "b.zip", map[string]string{"same.txt": "a\n", "changed.txt": "new\n"})
+
+	opts = defaultOptions()
+	opts.Plain = true
+	result, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip"))
+	if err != nil {
+		t.Fatalf("compareZipFiles: %v", err)
+	}
+
+	var output bytes.Buffer
+	console, colorOutput = &output, false
+	printResults(result)
+
+	for _, r := range output.String() {
+		if r > 0x7e && r != '\n' {
+			t.Fatalf("plain output should be ASCII, found %q in:\n%s", r, output.String())
+		}
+	}
+	for _, expected := range []string{"Identical files (1):\n  - same.txt", "Different files (1):", "\nThe ZIP files differ."} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("plain output should contain %q, got:\n%s", expected, output.String())
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if colorEnabled(colorAuto, file) {
+		t.Error("auto mode should not color output redirected to a file")
+	}
+	if !colorEnabled(colorAlways, file) {
+		t.Error("always mode should color redirected output")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !colorEnabled(colorAlways, file) {
+		t.Error("always mode should override NO_COLOR")
+	}
+	if colorEnabled(colorNever, file) {
+		t.Error("never mode should not color")
+	}
+}
+
+func TestSetupConsole(t *testing.T) {
+	savedOpts, savedConsole, savedColor := opts, console, colorOutput
+	defer func() { opts, console, colorOutput = savedOpts, savedConsole, savedColor }()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	opts = defaultOptions()
+	opts.Plain = true
+	setupConsole(file)
+	if console != file || colorOutput {
+		t.Errorf("--plain should write uncolored to the output, got %T, color %v", console, colorOutput)
+	}
+
+	opts.Color = colorAlways
+	setupConsole(file)
+	if !colorOutput {
+		t.Error("--plain --color=always should color")
+	}
+
+	opts = defaultOptions()
+	opts.Quiet = true
+	opts.Color = colorAlways
+	setupConsole(file)
+	if colorOutput {
+		t.Error("--quiet should not color")
+	}
+}
+
+func TestColorDiffLine(t *testing.T) {
+	saved := colorOutput
+	defer func() { colorOutput = saved }()
+
+	colorOutput = false
+	if got := colorDiffLine("-old"); got != "-old" {
+		t.Errorf("uncolored diff line should be unchanged, got %q", got)
+	}
+
+	colorOutput = true
+	tests := map[string]string{
+		"--- a/file.txt": ansiBold + "--- a/file.txt" + ansiReset,
+		"@@ -1 +1 @@":    ansiCyan + "@@ -1 +1 @@" + ansiReset,
+		"-old":           ansiRed + "-old" + ansiReset,
+		"+new":           ansiGreen + "+new" + ansiReset,
+		" context":       " context",
+	}
+	for line, expected := range tests {
+		if got := colorDiffLine(line); got != expected {
+			t.Errorf("colorDiffLine(%q) = %q, expected %q", line, got, expected)
+		}
+	}
+}
+
+func TestShowDiff(t *testing.T) {
+	savedOpts, savedConsole, savedColor := opts, console, colorOutput
+	defer func() { opts, console, colorOutput = savedOpts, savedConsole, savedColor }()
+
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{
+		"notes.txt": "a → b\n1\n2\n3\n",
+		"app.bin":   "\x00\x01",
+		"same.txt":  "same\n",
+	})
+	createTestZipIn(t, dir, "b.zip", map[string]string{
+		"notes.txt": "a → c\n1\n2\n4\n",
+		"app.bin":   "\x00\x02",
+		"same.txt":  "same\n",
+	})
+
+	opts = defaultOptions()
+	opts.ShowDiff = true
+	opts.Plain = true
+	result, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip"))
+	if err != nil {
+		t.Fatalf("compareZipFiles: %v", err)
+	}
+
+	var output bytes.Buffer
+	console, colorOutput = &output, false
+	printResults(result)
+
+	for _, expected := range []string{
+		"--- a/notes.txt\n+++ b/notes.txt\n@@ -1,4 +1,4 @@\n-a → b\n+a → c\n",
+		"Binary files a/app.bin and b/app.bin differ\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("output should contain %q, got:\n%s", expected, output.String())
+		}
+	}
+	if strings.Contains(output.String(), "a/same.txt") {
+		t.Error("identical files should have no diff")
+	}
+
+	// Limited to two lines after the file headers
+	output.Reset()
+	opts.DiffLines = 2
+	printDiffs(&output, result.DiffDetails)
+	if !strings.Contains(output.String(), "+++ b/notes.txt\n@@ -1,4 +1,4 @@\n-a → b\n... 5 more lines, see --diff-lines\n") {
+		t.Errorf("diff should be truncated after 2 lines, got:\n%s", output.String())
+	}
+}
+
+func TestShowDiffHighlightsNextToInsertion(t *testing.T) {
+	savedOpts, savedColor := opts, colorOutput
+	defer func() { opts, colorOutput = savedOpts, savedColor }()
+
+	opts = defaultOptions()
+	colorOutput = true
+
+	// The modified line and the inserted line after it form one change block
+	diff := generateDiff("line1\nline2\nline3\n", "line1\nline2 changed\ninserted\nline3\n", "f.txt")
+	var output bytes.Buffer
+	printDiffs(&output, []DiffInfo{{FileName: "f.txt", Diff: diff}})
+
+	expected := ansiRed + "-line2" + ansiReset + "\n" +
+		ansiGreen + "+line2" + ansiReverse + " changed" + ansiNoReverse + ansiReset + "\n" +
+		ansiGreen + "+inserted" + ansiReset + "\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("modified line should be highlighted, got:\n%q", output.String())
+	}
+}
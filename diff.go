@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// editOp identifies a single step of an edit script
+type editOp int
+
+const (
+	editEqual editOp = iota
+	editDelete
+	editInsert
+)
+
+// edit is one step of an edit script turning a into b.
+// A indexes into a (editEqual, editDelete), B indexes into b (editEqual, editInsert).
+type edit struct {
+	Op editOp
+	A  int
+	B  int
+}
+
+// myersDiff computes a minimal edit script turning a into b using Myers'
+// O(ND) difference algorithm in its linear space variant: instead of keeping
+// the frontier of every step for backtracking, it searches from both ends for
+// the middle of a shortest edit path and recurses on the parts before and after
+// it, so memory stays proportional to the input even when nothing is in common
+func myersDiff[T comparable](a, b []T) []edit {
+	edits, _ := boundedMyersDiff(a, b, 0)
+	return edits
+}
+
+// boundedMyersDiff is myersDiff giving up once a and b turn out to need more
+// than maxEdits insertions and deletions, 0 for no limit. The search costs
+// O((N+M)·maxEdits) then, however long the input is.
+func boundedMyersDiff[T comparable](a, b []T, maxEdits int) ([]edit, bool) {
+	s := &myersSearch[T]{
+		a:        a,
+		b:        b,
+		maxEdits: maxEdits,
+		forward:  make([]int, len(a)+len(b)+3),
+		backward: make([]int, len(a)+len(b)+3),
+		edits:    make([]edit, 0, len(a)+len(b)),
+	}
+	s.compare(0, len(a), 0, len(b))
+
+	// An equal edit covers an element of both a and b, every other edit one,
+	// so the script has 2*len(edits)-len(a)-len(b) insertions and deletions
+	if s.exceeded || maxEdits > 0 && 2*len(s.edits)-len(a)-len(b) > maxEdits {
+		return nil, false
+	}
+	deletionsFirst(s.edits)
+	return s.edits, true
+}
+
+// deletionsFirst moves the deletions of each run of changes before its
+// insertions, as unified diffs and the line pairing of the reports expect.
+// The search emits them in whatever order its split points give.
+func deletionsFirst(edits []edit) {
+	for start := 0; start < len(edits); start++ {
+		if edits[start].Op == editEqual {
+			continue
+		}
+		end := start
+		for end < len(edits) && edits[end].Op != editEqual {
+			end++
+		}
+		run := edits[start:end]
+		sort.SliceStable(run, func(i, j int) bool {
+			return run[i].Op == editDelete && run[j].Op == editInsert
+		})
+		start = end
+	}
+}
+
+// myersSearch holds the state of one myersDiff call. The frontiers are reused
+// by every step of the recursion, the edit script grows in order.
+type myersSearch[T comparable] struct {
+	a, b     []T
+	maxEdits int   // Edit distance at which the search gives up, 0 for no limit
+	exceeded bool  // Set when the search gave up, edits is incomplete then
+	forward  []int // Furthest reaching x per diagonal, searching from the start
+	backward []int // Furthest reaching x per diagonal, searching from the end
+	edits    []edit
+}
+
+// compare appends the edit script turning a[aLo:aHi] into b[bLo:bHi]
+func (s *myersSearch[T]) compare(aLo, aHi, bLo, bHi int) {
+	// Strip common prefix and suffix, they never take part in the search
+	for aLo < aHi && bLo < bHi && s.a[aLo] == s.b[bLo] {
+		s.edits = append(s.edits, edit{Op: editEqual, A: aLo, B: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && s.a[aHi-1-suffix] == s.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			s.edits = append(s.edits, edit{Op: editInsert, B: y})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			s.edits = append(s.edits, edit{Op: editDelete, A: x})
+		}
+	default:
+		// Both ranges start and end with differing elements, so the shortest
+		// path has at least two edits and the split point lies strictly inside
+		x, y := s.middleSnake(aLo, aHi, bLo, bHi)
+		if s.exceeded {
+			return
+		}
+		s.compare(aLo, x, bLo, y)
+		s.compare(x, aHi, y, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		s.edits = append(s.edits, edit{Op: editEqual, A: aHi + i, B: bHi + i})
+	}
+}
+
+// middleSnake runs the greedy Myers search forward from the start and backward
+// from the end of the ranges until both meet, and returns the point where they
+// do, which lies on a shortest edit path
+func (s *myersSearch[T]) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	delta := n - m
+	odd := delta%2 != 0
+
+	// Diagonal k = x-y of the forward search is diagonal delta-k of the
+	// backward one, which counts x and y from the end of the ranges
+	s.forward[offset+1] = 0
+	s.backward[offset+1] = 0
+	// The searches meet after at most maxD steps
+	for d := 0; ; d++ {
+		// Searching on means the shortest path has at least 2d-1 edits
+		if s.maxEdits > 0 && 2*d-1 > s.maxEdits {
+			s.exceeded = true
+			return aLo, bLo
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && s.forward[offset+k-1] < s.forward[offset+k+1]) {
+				x = s.forward[offset+k+1] // step down: insertion
+			} else {
+				x = s.forward[offset+k-1] + 1 // step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && s.a[aLo+x] == s.b[bLo+y] {
+				x++
+				y++
+			}
+			s.forward[offset+k] = x
+
+			if back := delta - k; odd && back >= -(d-1) && back <= d-1 && x+s.backward[offset+back] >= n {
+				return aLo + x, bLo + y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && s.backward[offset+k-1] < s.backward[offset+k+1]) {
+				x = s.backward[offset+k+1]
+			} else {
+				x = s.backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && s.a[aHi-1-x] == s.b[bHi-1-y] {
+				x++
+				y++
+			}
+			s.backward[offset+k] = x
+
+			if fwd := delta - k; !odd && fwd >= -d && fwd <= d && s.forward[offset+fwd]+x >= n {
+				return aHi - x, bHi - y
+			}
+		}
+	}
+}
+
+// splitLines splits content into lines, each keeping its trailing newline.
+// Only the last line may lack a newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats one side of a unified diff hunk header.
+// A single line omits the count, an empty range points at the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// writeDiffLine writes a single diff line and marks a missing final newline
+func writeDiffLine(diff *strings.Builder, prefix byte, line string) {
+	diff.WriteByte(prefix)
+	diff.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		diff.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// writeUnifiedHunks writes the edit script as unified diff hunks, keeping
+// context unchanged lines around every change. Changes separated by no more
+// than 2*context unchanged lines share one hunk.
+func writeUnifiedHunks(diff *strings.Builder, lines1, lines2 []string, edits []edit, context int) {
+	// Positions in both files before each edit, needed for hunk headers
+	posA := make([]int, len(edits)+1)
+	posB := make([]int, len(edits)+1)
+	for i, e := range edits {
+		posA[i+1], posB[i+1] = posA[i], posB[i]
+		if e.Op != editInsert {
+			posA[i+1]++
+		}
+		if e.Op != editDelete {
+			posB[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == editEqual {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].Op != editEqual {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+
+		first := max(0, i-context)
+		last := min(len(edits), end+context+1)
+
+		diff.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(posA[first], posA[last]-posA[first]),
+			hunkRange(posB[first], posB[last]-posB[first])))
+
+		for _, e := range edits[first:last] {
+			switch e.Op {
+			case editEqual:
+				writeDiffLine(diff, ' ', lines1[e.A])
+			case editDelete:
+				writeDiffLine(diff, '-', lines1[e.A])
+			case editInsert:
+				writeDiffLine(diff, '+', lines2[e.B])
+			}
+		}
+
+		i = last
+	}
+}
+
+// withinDiffSize reports whether a file is small enough to be held in memory for a diff
+func withinDiffSize(size int64) bool {
+	return opts.MaxDiffSize == 0 || size <= opts.MaxDiffSize
+}
+
+// generateDiff creates a unified diff with opts.ContextLines lines of context
+func generateDiff(content1, content2, fileName string) string {
+	return generateDiffBetween(content1, content2, fileName, fileName)
+}
+
+// generateDiffBetween creates a unified diff between two files with different names
+func generateDiffBetween(content1, content2, fileName1, fileName2 string) string {
+	if content1 == content2 {
+		return ""
+	}
+
+	lines1 := splitLines(content1)
+	lines2 := splitLines(content2)
+
+	var diff strings.Builder
+	diff.WriteString(fmt.Sprintf("--- a/%s\n", fileName1))
+	diff.WriteString(fmt.Sprintf("+++ b/%s\n", fileName2))
+
+	// Lines are matched by their comparison keys but printed as they are
+	edits := myersDiff(comparisonKeys(lines1), comparisonKeys(lines2))
+	writeUnifiedHunks(&diff, lines1, lines2, edits, opts.ContextLines)
+
+	return diff.String()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMyersDiffMinimal(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		deletions int
+		inserts   int
+	}{
+		{"abc", "abc", 0, 0},
+		{"", "abc", 0, 3},
+		{"abc", "", 3, 0},
+		{"abcabba", "cbabac", 3, 2}, // classic example from the Myers paper, D = 5
+		{"xabc", "abc", 1, 0},
+		{"abc", "abxc", 0, 1},
+		{"kitten", "sitting", 2, 3},
+		{"ABCBDAB", "BDCABA", 3, 2}, // LCS of length 4
+		{"abcdefghij", "jihgfedcba", 9, 9},
+	}
+
+	for _, test := range tests {
+		a := strings.Split(test.a, "")
+		b := strings.Split(test.b, "")
+		if test.a == "" {
+			a = nil
+		}
+		if test.b == "" {
+			b = nil
+		}
+
+		edits := myersDiff(a, b)
+
+		// Replaying the script must reproduce b
+		var rebuilt []string
+		deletions, inserts := 0, 0
+		for i, e := range edits {
+			switch e.Op {
+			case editEqual:
+				if a[e.A] != b[e.B] {
+					t.Errorf("myersDiff(%q, %q): equal edit on differing elements", test.a, test.b)
+				}
+				rebuilt = append(rebuilt, a[e.A])
+			case editDelete:
+				// Within a run of changes, deletions come first
+				if i > 0 && edits[i-1].Op == editInsert {
+					t.Errorf("myersDiff(%q, %q): deletion after an insertion at edit %d", test.a, test.b, i)
+				}
+				deletions++
+			case editInsert:
+				inserts++
+				rebuilt = append(rebuilt, b[e.B])
+			}
+		}
+
+		if strings.Join(rebuilt, "") != test.b {
+			t.Errorf("myersDiff(%q, %q) rebuilt %q", test.a, test.b, strings.Join(rebuilt, ""))
+		}
+		if deletions+inserts != test.deletions+test.inserts {
+			t.Errorf("myersDiff(%q, %q) = %d edits; want %d", test.a, test.b, deletions+inserts, test.deletions+test.inserts)
+		}
+	}
+}
+
+func TestMyersDiffLargeChange(t *testing.T) {
+	// Every line changed: D = 20000, which used to record D frontier snapshots
+	const lines = 10000
+	a := make([]string, lines)
+	b := make([]string, lines)
+	for i := range a {
+		a[i] = fmt.Sprintf("old line %d", i)
+		b[i] = fmt.Sprintf("new line %d", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := myersDiff(a, b)
+	runtime.ReadMemStats(&after)
+
+	deletions, inserts := 0, 0
+	for _, e := range edits {
+		switch e.Op {
+		case editDelete:
+			deletions++
+		case editInsert:
+			inserts++
+		default:
+			t.Fatalf("unexpected equal edit %+v", e)
+		}
+	}
+	if deletions != lines || inserts != lines {
+		t.Errorf("got %d deletions and %d inserts, expected %d each", deletions, inserts, lines)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Errorf("myersDiff allocated %d bytes, expected memory linear in the input", allocated)
+	}
+}
+
+func TestGenerateDiffInsertedLine(t *testing.T) {
+	content1 := "line 1\nline 2\nline 3\n"
+	content2 := "new first line\nline 1\nline 2\nline 3\n"
+
+	diff := generateDiff(content1, content2, "config.xml")
+
+	if !strings.Contains(diff, "+new first line\n") {
+		t.Errorf("diff should report inserted line, got:\n%s", diff)
+	}
+
+	for _, line := range []string{"-line 1", "-line 2", "-line 3", "+line 1"} {
+		if strings.Contains(diff, line+"\n") {
+			t.Errorf("diff should not report unchanged %q, got:\n%s", line, diff)
+		}
+	}
+}
+
+func TestGenerateDiffUnifiedHunks(t *testing.T) {
+	content1 := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	content2 := "1\n2\nthree\n4\n5\n6\n7\n8\n9\nten\n"
+
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.ContextLines = 1
+	expected := "--- a/f.txt\n+++ b/f.txt\n" +
+		"@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n" +
+		"@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n"
+	if diff := generateDiff(content1, content2, "f.txt"); diff != expected {
+		t.Errorf("generateDiff with context 1 =\n%s\nwant\n%s", diff, expected)
+	}
+
+	// With enough context both changes share one hunk
+	opts.ContextLines = 3
+	diff := generateDiff(content1, content2, "f.txt")
+	if strings.Count(diff, "@@ -") != 1 || !strings.Contains(diff, "@@ -1,10 +1,10 @@\n") {
+		t.Errorf("generateDiff with context 3 should produce a single hunk, got:\n%s", diff)
+	}
+}
+
+func TestGenerateDiffDeletionsFirst(t *testing.T) {
+	// A modified line next to an inserted one forms a single change block
+	diff := generateDiff("line1\nline2\n", "inserted\nline1 changed\nline2\n", "f.txt")
+
+	expected := "--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,3 @@\n-line1\n+inserted\n+line1 changed\n line2\n"
+	if diff != expected {
+		t.Errorf("generateDiff =\n%s\nwant\n%s", diff, expected)
+	}
+}
+
+func TestGenerateDiffMissingFinalNewline(t *testing.T) {
+	diff := generateDiff("a\nb", "a\nb\n", "f.txt")
+
+	expected := "--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"
+	if diff != expected {
+		t.Errorf("generateDiff =\n%s\nwant\n%s", diff, expected)
+	}
+}
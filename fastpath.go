@@ -0,0 +1,75 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+)
+
+// zipChecksum identifies the content of a ZIP entry by its central directory fields
+type zipChecksum struct {
+	crc32 uint32
+	size  uint64
+}
+
+// zipChecksums reads the central directory of a ZIP file and returns the
+// checksums of its entries keyed by normalized path. Keys shared by several
+// entries are left out, as it is open which entry is compared.
+func zipChecksums(zipPath string) (map[string]zipChecksum, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	checksums := make(map[string]zipChecksum)
+	ambiguous := make(map[string]bool)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		key := normalizeEntryPath(file.Name)
+		if _, exists := checksums[key]; exists || ambiguous[key] {
+			delete(checksums, key)
+			ambiguous[key] = true
+			continue
+		}
+		checksums[key] = zipChecksum{crc32: file.CRC32, size: file.UncompressedSize64}
+	}
+
+	return checksums, nil
+}
+
+// unchangedEntries returns the normalized paths of the top level entries with
+// equal CRC32 and uncompressed size in both archives. These entries are not
+// decompressed and hashed, see entryCollector.readArchive. The result is nil
+// if either archive is no ZIP file, all entries are read then.
+func unchangedEntries(path1, path2 string) map[string]bool {
+	checksums1, err := zipChecksums(path1)
+	if err != nil {
+		return nil
+	}
+	checksums2, err := zipChecksums(path2)
+	if err != nil {
+		return nil
+	}
+
+	unchanged := make(map[string]bool)
+	for key, checksum := range checksums1 {
+		if checksums2[key] == checksum {
+			unchanged[key] = true
+		}
+	}
+	return unchanged
+}
+
+// checksumFileInfo describes an unchanged entry by its central directory
+// checksum instead of reading its content
+func checksumFileInfo(name, key string, entry *archiveEntry) FileInfo {
+	return FileInfo{
+		Name:     name,
+		BaseName: key,
+		Size:     entry.Size,
+		Hash:     fmt.Sprintf("crc32:%08x", entry.CRC32),
+		Metadata: entry.Metadata,
+	}
+}
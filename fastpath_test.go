@@ -0,0 +1,174 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// createRawTestZip writes a stored ZIP entry whose central directory claims
+// the CRC32 of claimed, while the data is content
+func createRawTestZip(t *testing.T, zipPath, content, claimed string) {
+	t.Helper()
+
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	writer, err := zipWriter.CreateRaw(&zip.FileHeader{
+		Name:               "file.txt",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(claimed)),
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	writer.Write([]byte(content))
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+}
+
+func TestFastPathSkipsEntriesWithEqualCRC(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	dir := t.TempDir()
+	zip1 := filepath.Join(dir, "a.zip")
+	zip2 := filepath.Join(dir, "b.zip")
+	createRawTestZip(t, zip1, "aaaa", "aaaa")
+	// Same CRC and size in the central directory, but other data
+	createRawTestZip(t, zip2, "bbbb", "aaaa")
+
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if hasDifferences(result) {
+		t.Errorf("Fast path should trust the central directory, got %v", result.Different)
+	}
+
+	// --strict decompresses the entry and notices the checksum mismatch
+	opts.Strict = true
+	if _, err := compareZipFiles(zip1, zip2); !errors.Is(err, zip.ErrChecksum) {
+		t.Errorf("Expected checksum error in strict mode, got %v", err)
+	}
+}
+
+func TestFastPathStillDiffsChangedEntries(t *testing.T) {
+	result := compareTestZips(t,
+		map[string]string{"same.txt": "same\n", "changed.txt": "old\n", "moved/a.txt": "moved\n"},
+		map[string]string{"same.txt": "same\n", "changed.txt": "new\n", "b/a.txt": "moved\n"})
+
+	if len(result.Identical) != 1 || len(result.Different) != 1 || len(result.Renamed) != 1 {
+		t.Fatalf("Unexpected result: identical %v, different %v, renamed %v", result.Identical, result.Different, result.Renamed)
+	}
+	if result.DiffDetails[0].Diff == "" {
+		t.Error("Expected a diff for changed.txt")
+	}
+}
+
+func TestUnchangedEntriesRequiresZip(t *testing.T) {
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"file.txt": "content"})
+	tarPath := createTestTarIn(t, dir, "b.tar", map[string]string{"file.txt": "content"})
+
+	if unchanged := unchangedEntries(filepath.Join(dir, "a.zip"), tarPath); unchanged != nil {
+		t.Errorf("Expected no fast path for ZIP against tar, got %v", unchanged)
+	}
+}
+
+// createBenchmarkZips writes two ZIP files with entries of pseudo-random
+// content that differ in a single entry
+func createBenchmarkZips(b *testing.B, entries, size int) (string, string) {
+	b.Helper()
+
+	dir := b.TempDir()
+	random := rand.New(rand.NewSource(1))
+	contents := make([][]byte, entries)
+	for i := range contents {
+		contents[i] = make([]byte, size)
+		random.Read(contents[i])
+	}
+
+	write := func(name string, changed int) string {
+		zipPath := filepath.Join(dir, name)
+		file, err := os.Create(zipPath)
+		if err != nil {
+			b.Fatalf("Failed to create ZIP: %v", err)
+		}
+		defer file.Close()
+
+		zipWriter := zip.NewWriter(file)
+		for i, content := range contents {
+			writer, err := zipWriter.Create(fmt.Sprintf("data/file%03d.bin", i))
+			if err != nil {
+				b.Fatalf("Failed to create entry: %v", err)
+			}
+			if i == changed {
+				content = append([]byte("changed"), content...)
+			}
+			writer.Write(content)
+		}
+		if err := zipWriter.Close(); err != nil {
+			b.Fatalf("Failed to close ZIP: %v", err)
+		}
+		return zipPath
+	}
+
+	return write("a.zip", -1), write("b.zip", 0)
+}
+
+func BenchmarkCompareZipFiles(b *testing.B) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	zip1, zip2 := createBenchmarkZips(b, 100, 256*1024)
+
+	for _, strict := range []bool{false, true} {
+		name := "fast"
+		if strict {
+			name = "strict"
+		}
+		b.Run(name, func(b *testing.B) {
+			opts.Strict = strict
+			for i := 0; i < b.N; i++ {
+				if _, err := compareZipFiles(zip1, zip2); err != nil {
+					b.Fatalf("compareZipFiles failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFastPathKeepsNormalizedFiles(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts = defaultOptions()
+	opts.IgnoreLines = []string{`^Built: `}
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	// Equal entries with a filtered line are listed as normalized like with --strict
+	files := map[string]string{"build.txt": "Built: today\nversion 1\n", "plain.txt": "text\n"}
+	for _, strict := range []bool{false, true} {
+		opts.Strict = strict
+		result := compareTestZips(t, files, files)
+		if !reflect.DeepEqual(result.Normalized, []string{"build.txt"}) {
+			t.Errorf("strict %v: normalized = %v, expected [build.txt]", strict, result.Normalized)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the name of the file with exclude patterns read next to the archives
+const ignoreFileName = ".zipcompareignore"
+
+// matchGlob reports whether a slash separated path matches a glob pattern.
+// "**" matches any number of path segments, the other wildcards work like
+// path.Match within one segment. A pattern without slash matches the last
+// segment in any directory, a pattern ending in a slash everything below it.
+func matchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// validateGlob checks a pattern for syntax errors like an unclosed bracket
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// entryFilter decides which archive entries are compared. A nil filter accepts everything.
+type entryFilter struct {
+	include []string // If not empty, only files matching one of these are compared
+	exclude []string // Entries matching one of these are ignored, including nested archives
+}
+
+// includes reports whether a file passes the include patterns.
+// Each pattern is matched against the stored and the normalized path.
+func (f *entryFilter) includes(names ...string) bool {
+	if f == nil || len(f.include) == 0 {
+		return true
+	}
+	return matchAnyGlob(f.include, names)
+}
+
+// excludes reports whether an entry matches one of the exclude patterns
+func (f *entryFilter) excludes(names ...string) bool {
+	if f == nil {
+		return false
+	}
+	return matchAnyGlob(f.exclude, names)
+}
+
+// matchAnyGlob reports whether any of the names matches any of the patterns
+func matchAnyGlob(patterns, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if matchGlob(pattern, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// comparisonFilter combines the configured include and exclude patterns with
+// the ignore files in the directories containing the two archives
+func comparisonFilter(path1, path2 string) (*entryFilter, error) {
+	filter := &entryFilter{
+		include: opts.Include,
+		exclude: append([]string(nil), opts.Exclude...),
+	}
+
+	dir1 := filepath.Dir(filepath.Clean(path1))
+	dir2 := filepath.Dir(filepath.Clean(path2))
+	dirs := []string{dir1}
+	if dir2 != dir1 {
+		dirs = append(dirs, dir2)
+	}
+
+	for _, dir := range dirs {
+		patterns, err := loadIgnoreFile(filepath.Join(dir, ignoreFileName))
+		if err != nil {
+			return nil, err
+		}
+		filter.exclude = append(filter.exclude, patterns...)
+	}
+
+	return filter, nil
+}
+
+// loadIgnoreFile reads exclude patterns, one per line. Empty lines and lines
+// starting with # are skipped. A missing file yields no patterns.
+func loadIgnoreFile(ignorePath string) ([]string, error) {
+	data, err := os.ReadFile(ignorePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file %s: %w", ignorePath, err)
+	}
+
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := validateGlob(line); err != nil {
+			return nil, fmt.Errorf("%s: %w", ignorePath, err)
+		}
+		patterns = append(patterns, line)
+	}
+
+	return patterns, nil
+}
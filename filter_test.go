@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "logs/app.log", true},
+		{"*.log", "app.log.txt", false},
+		{"META-INF/MANIFEST.MF", "META-INF/MANIFEST.MF", true},
+		{"META-INF/MANIFEST.MF", "lib/META-INF/MANIFEST.MF", false},
+		{"**/META-INF/MANIFEST.MF", "lib/META-INF/MANIFEST.MF", true},
+		{"**/META-INF/MANIFEST.MF", "META-INF/MANIFEST.MF", true},
+		{"src/**/*.java", "src/a/b/Foo.java", true},
+		{"src/**/*.java", "src/Foo.java", true},
+		{"src/**/*.java", "test/Foo.java", false},
+		{"build/", "build/out/a.txt", true},
+		{"build/", "src/build/a.txt", false},
+		{"/docs/*.md", "docs/readme.md", true},
+		{"file?.txt", "file1.txt", true},
+		{"[ab].txt", "c.txt", false},
+	}
+
+	for _, test := range tests {
+		if got := matchGlob(test.pattern, test.name); got != test.expected {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", test.pattern, test.name, got, test.expected)
+		}
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	if err := validateGlob("**/*.log"); err != nil {
+		t.Errorf("Expected valid pattern, got %v", err)
+	}
+	if err := validateGlob("[a.log"); err == nil {
+		t.Error("Expected error for unclosed bracket")
+	}
+}
+
+func TestCompareWithFilters(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	files1 := map[string]string{"app.class": "a", "build.log": "one", "META-INF/MANIFEST.MF": "x"}
+	files2 := map[string]string{"app.class": "a", "build.log": "two", "META-INF/MANIFEST.MF": "y"}
+
+	opts.Exclude = []string{"*.log", "META-INF/MANIFEST.MF"}
+	result := compareTestZips(t, files1, files2)
+	if hasDifferences(result) {
+		t.Errorf("Expected no differences with excluded noise, got %v", result.Different)
+	}
+	if !reflect.DeepEqual(result.Ignored, []string{"META-INF/MANIFEST.MF", "build.log"}) {
+		t.Errorf("Unexpected ignored entries: %v", result.Ignored)
+	}
+	if summary := buildSummary(result); summary.Ignored != 2 || summary.Total != 1 {
+		t.Errorf("Expected 2 ignored and 1 compared file, got %+v", summary)
+	}
+
+	opts.Exclude = nil
+	opts.Include = []string{"*.class"}
+	result = compareTestZips(t, files1, files2)
+	if hasDifferences(result) || len(result.Identical) != 1 {
+		t.Errorf("Expected only app.class to be compared, got %+v", result)
+	}
+}
+
+func TestIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"file.txt": "same", "build-info.properties": "1"})
+	createTestZipIn(t, dir, "b.zip", map[string]string{"file.txt": "same", "build-info.properties": "2"})
+
+	ignore := "# generated at build time\n\nbuild-info.properties\n"
+	if err := os.WriteFile(filepath.Join(dir, ignoreFileName), []byte(ignore), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	result, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip"))
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if hasDifferences(result) {
+		t.Errorf("Expected build-info.properties to be ignored, got %v", result.Different)
+	}
+	if len(result.Ignored) != 1 {
+		t.Errorf("Expected 1 ignored entry, got %v", result.Ignored)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ignoreFileName), []byte("[broken\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	if _, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip")); err == nil {
+		t.Error("Expected error for invalid pattern in ignore file")
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultLanguage is used when neither --lang nor the environment selects a supported language
+const defaultLanguage = "en"
+
+// messages holds the console and HTML report texts of each supported language.
+// Values are fmt format strings, every language must define the same keys with the same verbs.
+var messages = map[string]map[string]string{
+	"en": {
+		// Single comparison
+		"title":             "=== ZIP file comparison ===",
+		"modes":             "Comparison modes",
+		"identicalFiles":    "Identical files",
+		"differentFiles":    "Different files",
+		"onlyInFirst":       "Only in the first ZIP file",
+		"onlyInSecond":      "Only in the second ZIP file",
+		"renamed":           "Renamed/moved",
+		"similar":           "%.0f%% similar",
+		"metadataDiffers":   "Metadata differs",
+		"archive":           "Archive",
+		"normalized":        "Line filters applied",
+		"warnings":          "Warnings",
+		"summary":           "Summary",
+		"totalFiles":        "Total files",
+		"identical":         "Identical",
+		"different":         "Different",
+		"onlyInZip1":        "Only in ZIP 1",
+		"onlyInZip2":        "Only in ZIP 2",
+		"ignored":           "Ignored",
+		"archivesIdentical": "The ZIP files are identical!",
+		"archivesDiffer":    "The ZIP files differ.",
+		"reportSaved":       "%s report saved: %s",
+		"binaryDiffer":      "Binary files a/%[1]s and b/%[1]s differ",
+		"diffSkipped":       "Files a/%[1]s and b/%[1]s differ, diff skipped because the file exceeds the maximum diff size",
+		"diffTruncated":     "... %d more lines, see --diff-lines",
+
+		// Directory mode
+		"searching":      "Searching for ZIP files in directories...",
+		"directory1":     "Directory 1",
+		"directory2":     "Directory 2",
+		"noPairs":        "No matching ZIP files found!",
+		"pairsFound":     "%d matching ZIP pairs found:",
+		"onlyInDir1":     "Only in directory 1",
+		"onlyInDir2":     "Only in directory 2",
+		"comparing":      "Comparing %d/%d: %s",
+		"compareFailed":  "Error while comparing: %v",
+		"pairSummary":    "📁 Files: %d | ✅ Identical: %d | ⚠️  Different: %d | 📋 Only in 1: %d | 📋 Only in 2: %d | 🔀 Renamed: %d",
+		"reportFailed":   "Error while writing the %s report: %v",
+		"pairReport":     "%s report: %s",
+		"overview":       "Overview: %s",
+		"allDone":        "All comparisons finished!",
+		"allDoneReports": "All comparisons finished! %s reports saved in: %s",
+
+		// Warnings and errors
+		"warnCollision":    "%s: entries %q and %q both map to %q, comparing %q only",
+		"warnNotArchive":   "%s: %s is not a valid archive, comparing it as a file",
+		"warnDiffSkipped":  "%s: diff skipped, file exceeds the maximum diff size of %d bytes",
+		"error":            "Error: %v",
+		"errorOutputTwice": "Error: output path given both as argument and with -o",
+		"errorAccess":      "Error accessing %s: %v",
+		"errorCompareDirs": "Error comparing directories: %v",
+		"errorCompareZips": "Error comparing ZIP files: %v",
+		"errorPathKinds":   "Error: both paths must be either files or directories, use --tree to compare an archive with a directory tree",
+
+		// Command line help
+		"usage": `Usage:
+  zipcompare [options] <zip1> <zip2> [output]  - Compare two ZIP files
+  zipcompare [options] <dir1> <dir2> [output]  - Compare ZIP files in directories
+  zipcompare --tree [options] <zip|dir> <zip|dir> [output]  - Compare with unpacked directory trees
+    If output is a file name, the report is saved to that file
+    In directory mode, output is a directory receiving one report per ZIP pair
+
+Exit codes:
+  0  no differences found
+  1  differences found
+  2  error`,
+		"usageOptions": "Options:",
+
+		// HTML reports
+		"html.title":            "ZIP comparison",
+		"html.indexTitle":       "ZIP comparison overview",
+		"html.generated":        "Generated",
+		"html.modes":            "Modes",
+		"html.file":             "File",
+		"html.field":            "Field",
+		"html.archive":          "archive",
+		"html.normalizedFiles":  "Normalized files",
+		"html.normalizedNote":   "Lines matching the configured line filters were removed from these files before comparing.",
+		"html.binary":           "binary",
+		"html.binaryDiffer":     "Binary files differ",
+		"html.diffSkipped":      "Files differ, diff skipped because the file exceeds the maximum diff size",
+		"html.renamedFiles":     "Renamed / moved files",
+		"html.similar":          "%s similar",
+		"html.showIdentical":    "Show identical files",
+		"html.pair":             "Pair",
+		"html.status":           "Status",
+		"html.total":            "Total",
+		"html.onlyIn1":          "Only in 1",
+		"html.onlyIn2":          "Only in 2",
+		"html.renamedShort":     "Renamed",
+		"html.status.identical": "identical",
+		"html.status.different": "different",
+		"html.status.error":     "error",
+	},
+	"de": {
+		// Single comparison
+		"title":             "=== ZIP-Datei Vergleich ===",
+		"modes":             "Vergleichsmodi",
+		"identicalFiles":    "Identische Dateien",
+		"differentFiles":    "Unterschiedliche Dateien",
+		"onlyInFirst":       "Nur in der ersten ZIP-Datei",
+		"onlyInSecond":      "Nur in der zweiten ZIP-Datei",
+		"renamed":           "Umbenannt/verschoben",
+		"similar":           "%.0f%% ähnlich",
+		"metadataDiffers":   "Metadaten unterschiedlich",
+		"archive":           "Archiv",
+		"normalized":        "Zeilenfilter angewendet",
+		"warnings":          "Warnungen",
+		"summary":           "Zusammenfassung",
+		"totalFiles":        "Gesamt Dateien",
+		"identical":         "Identisch",
+		"different":         "Unterschiedlich",
+		"onlyInZip1":        "Nur in ZIP 1",
+		"onlyInZip2":        "Nur in ZIP 2",
+		"ignored":           "Ignoriert",
+		"archivesIdentical": "Die ZIP-Dateien sind identisch!",
+		"archivesDiffer":    "Die ZIP-Dateien unterscheiden sich.",
+		"reportSaved":       "%s-Report gespeichert: %s",
+		"binaryDiffer":      "Binärdateien a/%[1]s und b/%[1]s sind verschieden",
+		"diffSkipped":       "Dateien a/%[1]s und b/%[1]s sind verschieden, Diff übersprungen, weil die Datei die maximale Diff-Größe überschreitet",
+		"diffTruncated":     "... %d weitere Zeilen, siehe --diff-lines",
+
+		// Directory mode
+		"searching":      "Suche nach ZIP-Dateien in Verzeichnissen...",
+		"directory1":     "Verzeichnis 1",
+		"directory2":     "Verzeichnis 2",
+		"noPairs":        "Keine passenden ZIP-Dateien gefunden!",
+		"pairsFound":     "%d passende ZIP-Paare gefunden:",
+		"onlyInDir1":     "Nur in Verzeichnis 1",
+		"onlyInDir2":     "Nur in Verzeichnis 2",
+		"comparing":      "Vergleiche %d/%d: %s",
+		"compareFailed":  "Fehler beim Vergleichen: %v",
+		"pairSummary":    "📁 Dateien: %d | ✅ Identisch: %d | ⚠️  Unterschiedlich: %d | 📋 Nur in 1: %d | 📋 Nur in 2: %d | 🔀 Umbenannt: %d",
+		"reportFailed":   "Fehler beim Erstellen des %s-Reports: %v",
+		"pairReport":     "%s-Report: %s",
+		"overview":       "Übersicht: %s",
+		"allDone":        "Alle Vergleiche abgeschlossen!",
+		"allDoneReports": "Alle Vergleiche abgeschlossen! %s-Reports gespeichert in: %s",
+
+		// Warnings and errors
+		"warnCollision":    "%s: Einträge %q und %q werden beide zu %q, nur %q wird verglichen",
+		"warnNotArchive":   "%s: %s ist kein gültiges Archiv, wird als Datei verglichen",
+		"warnDiffSkipped":  "%s: Diff übersprungen, die Datei überschreitet die maximale Diff-Größe von %d Bytes",
+		"error":            "Fehler: %v",
+		"errorOutputTwice": "Fehler: Ausgabepfad sowohl als Argument als auch mit -o angegeben",
+		"errorAccess":      "Fehler beim Zugriff auf %s: %v",
+		"errorCompareDirs": "Fehler beim Vergleichen der Verzeichnisse: %v",
+		"errorCompareZips": "Fehler beim Vergleichen der ZIP-Dateien: %v",
+		"errorPathKinds":   "Fehler: Beide Pfade müssen Dateien oder Verzeichnisse sein, --tree vergleicht ein Archiv mit einem Verzeichnisbaum",
+
+		// Command line help
+		"usage": `Aufruf:
+  zipcompare [Optionen] <zip1> <zip2> [Ausgabe]  - Zwei ZIP-Dateien vergleichen
+  zipcompare [Optionen] <dir1> <dir2> [Ausgabe]  - ZIP-Dateien in Verzeichnissen vergleichen
+  zipcompare --tree [Optionen] <zip|dir> <zip|dir> [Ausgabe]  - Mit entpackten Verzeichnisbäumen vergleichen
+    Ist die Ausgabe ein Dateiname, wird der Report in dieser Datei gespeichert
+    Im Verzeichnismodus ist die Ausgabe ein Verzeichnis, das einen Report pro ZIP-Paar erhält
+
+Exit-Codes:
+  0  keine Unterschiede gefunden
+  1  Unterschiede gefunden
+  2  Fehler`,
+		"usageOptions": "Optionen:",
+
+		// HTML reports
+		"html.title":            "ZIP-Vergleich",
+		"html.indexTitle":       "ZIP-Vergleich Übersicht",
+		"html.generated":        "Erstellt",
+		"html.modes":            "Modi",
+		"html.file":             "Datei",
+		"html.field":            "Feld",
+		"html.archive":          "Archiv",
+		"html.normalizedFiles":  "Normalisierte Dateien",
+		"html.normalizedNote":   "Zeilen, die auf die konfigurierten Zeilenfilter passen, wurden vor dem Vergleich aus diesen Dateien entfernt.",
+		"html.binary":           "binär",
+		"html.binaryDiffer":     "Binärdateien unterscheiden sich",
+		"html.diffSkipped":      "Dateien unterscheiden sich, Diff übersprungen, weil die Datei die maximale Diff-Größe überschreitet",
+		"html.renamedFiles":     "Umbenannte / verschobene Dateien",
+		"html.similar":          "%s ähnlich",
+		"html.showIdentical":    "Identische Dateien anzeigen",
+		"html.pair":             "Paar",
+		"html.status":           "Status",
+		"html.total":            "Gesamt",
+		"html.onlyIn1":          "Nur in 1",
+		"html.onlyIn2":          "Nur in 2",
+		"html.renamedShort":     "Umbenannt",
+		"html.status.identical": "identisch",
+		"html.status.different": "unterschiedlich",
+		"html.status.error":     "Fehler",
+	},
+}
+
+// supportedLanguages returns the languages of the message catalog in sorted order
+func supportedLanguages() []string {
+	languages := make([]string, 0, len(messages))
+	for language := range messages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// languageTag extracts the language from a locale like "de_DE.UTF-8" or "en-US"
+func languageTag(locale string) string {
+	language, _, _ := strings.Cut(strings.ToLower(locale), ".")
+	language, _, _ = strings.Cut(language, "@")
+	language, _, _ = strings.Cut(language, "_")
+	language, _, _ = strings.Cut(language, "-")
+	return language
+}
+
+// environmentLanguage returns the language selected by LC_ALL, LC_MESSAGES or LANG,
+// in this order of precedence, or defaultLanguage if it is unset or not supported
+func environmentLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		if _, ok := messages[languageTag(locale)]; ok {
+			return languageTag(locale)
+		}
+		return defaultLanguage
+	}
+	return defaultLanguage
+}
+
+// tr returns the message for key in the active language, formatted with args.
+// Without compiled options the default language is used.
+func tr(key string, args ...any) string {
+	format, ok := messages[opts.lang][key]
+	if !ok {
+		format = messages[defaultLanguage][key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// activeLanguage returns the language of console output and reports
+func activeLanguage() string {
+	if _, ok := messages[opts.lang]; ok {
+		return opts.lang
+	}
+	return defaultLanguage
+}
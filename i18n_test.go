@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMessageCatalogsAreComplete(t *testing.T) {
+	verbs := regexp.MustCompile(`%[^%a-zA-Z]*[a-zA-Z%]`)
+
+	for key, english := range messages[defaultLanguage] {
+		for _, language := range supportedLanguages() {
+			translated, ok := messages[language][key]
+			if !ok {
+				t.Errorf("%s: missing message %q", language, key)
+				continue
+			}
+			if got, want := verbs.FindAllString(translated, -1), verbs.FindAllString(english, -1); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s: message %q uses verbs %v, expected %v", language, key, got, want)
+			}
+		}
+	}
+	for _, language := range supportedLanguages() {
+		for key := range messages[language] {
+			if _, ok := messages[defaultLanguage][key]; !ok {
+				t.Errorf("%s: message %q does not exist in %s", language, key, defaultLanguage)
+			}
+		}
+	}
+}
+
+func TestLanguageSelection(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	tests := []struct {
+		lang, lcAll, lcMessages, envLang string
+		expected                         string
+	}{
+		{"", "", "", "", "en"},
+		{"", "", "", "de_DE.UTF-8", "de"},
+		{"", "", "", "C.UTF-8", "en"},
+		{"", "en_US.UTF-8", "", "de_DE.UTF-8", "en"},
+		{"", "", "de_AT", "en_GB", "de"},
+		{"", "fr_FR.UTF-8", "", "de_DE.UTF-8", "en"},
+		{"de", "en_US.UTF-8", "", "", "de"},
+		{"EN-us", "", "", "de_DE", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", tt.lcMessages)
+		t.Setenv("LANG", tt.envLang)
+
+		opts = defaultOptions()
+		opts.Lang = tt.lang
+		if err := opts.compile(); err != nil {
+			t.Fatalf("compile with lang %q: %v", tt.lang, err)
+		}
+		if opts.lang != tt.expected {
+			t.Errorf("lang %q, LC_ALL %q, LC_MESSAGES %q, LANG %q: got %q, expected %q",
+				tt.lang, tt.lcAll, tt.lcMessages, tt.envLang, opts.lang, tt.expected)
+		}
+	}
+
+	opts = defaultOptions()
+	opts.Lang = "fr"
+	if err := opts.compile(); err == nil {
+		t.Error("unsupported --lang should be rejected")
+	}
+}
+
+func TestLocalizedConsoleAndHTML(t *testing.T) {
+	savedOpts, savedConsole := opts, console
+	defer func() { opts, console = savedOpts, savedConsole }()
+
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"same.txt": "a\n", "changed.txt": "old\n"})
+	createTestZipIn(t, dir, "b.zip", map[string]string{"same.txt": "a\n", "changed.txt": "new\n"})
+	zip1, zip2 := filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip")
+
+	for _, tt := range []struct {
+		lang     string
+		console  []string
+		html     []string
+		notHTML  string
+		notPrint string
+	}{
+		{"en", []string{"Identical files (1)", "Different files (1)", "The ZIP files differ."},
+			[]string{`<html lang="en">`, "<h2>Summary</h2>"}, "Zusammenfassung", "Identische Dateien"},
+		{"de", []string{"Identische Dateien (1)", "Unterschiedliche Dateien (1)", "Die ZIP-Dateien unterscheiden sich."},
+			[]string{`<html lang="de">`, "<h2>Zusammenfassung</h2>"}, "<h2>Summary</h2>", "Identical files"},
+	} {
+		opts = defaultOptions()
+		opts.Lang = tt.lang
+		if err := opts.compile(); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := compareZipFiles(zip1, zip2)
+		if err != nil {
+			t.Fatalf("compareZipFiles: %v", err)
+		}
+
+		var output bytes.Buffer
+		console = &output
+		printResults(result)
+		for _, expected := range tt.console {
+			if !strings.Contains(output.String(), expected) {
+				t.Errorf("%s: console output should contain %q, got:\n%s", tt.lang, expected, output.String())
+			}
+		}
+		if strings.Contains(output.String(), tt.notPrint) {
+			t.Errorf("%s: console output should not contain %q", tt.lang, tt.notPrint)
+		}
+
+		htmlFile := filepath.Join(t.TempDir(), "report.html")
+		if err := generateHTMLReport(result, zip1, zip2, htmlFile); err != nil {
+			t.Fatalf("generateHTMLReport: %v", err)
+		}
+		content, err := os.ReadFile(htmlFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range tt.html {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("%s: HTML report should contain %q", tt.lang, expected)
+			}
+		}
+		if strings.Contains(string(content), tt.notHTML) {
+			t.Errorf("%s: HTML report should not contain %q", tt.lang, tt.notHTML)
+		}
+	}
+}
+
+func TestLocalizedWarnings(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"big.txt": "old content\n", "lib.jar": "not a zip"})
+	createTestZipIn(t, dir, "b.zip", map[string]string{"big.txt": "new content\n", "lib.jar": "not a zip"})
+
+	opts = defaultOptions()
+	opts.Lang = "de"
+	opts.MaxDiffSize = 4
+	opts.Recursive = true
+	if err := opts.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip"))
+	if err != nil {
+		t.Fatalf("compareZipFiles: %v", err)
+	}
+
+	warnings := strings.Join(result.Warnings, "\n")
+	for _, expected := range []string{
+		"big.txt: Diff übersprungen, die Datei überschreitet die maximale Diff-Größe von 4 Bytes",
+		"lib.jar ist kein gültiges Archiv, wird als Datei verglichen",
+	} {
+		if !strings.Contains(warnings, expected) {
+			t.Errorf("warnings should contain %q, got:\n%s", expected, warnings)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Values of --intraline
+const (
+	intralineWord = "word" // Highlight changed words, whitespace runs and punctuation
+	intralineChar = "char" // Highlight changed characters
+	intralineNone = "none" // Only mark whole lines as changed
+)
+
+// maxIntralineEdits limits the changed tokens searched for in a line pair.
+// Line pairs differing in more, like rewritten minified files, only get their
+// common prefix and suffix trimmed, so a single long line never makes the
+// comparison slow. A long line with a few changes still gets exact spans.
+const maxIntralineEdits = 1000
+
+// Span is a changed range of a line, as byte offsets from Start to End (exclusive)
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// LineChange is a deleted line of a diff paired with the inserted line replacing it,
+// with the changed spans of both
+type LineChange struct {
+	OldLine  int    `json:"oldLine"` // Line number in the first file
+	NewLine  int    `json:"newLine"` // Line number in the second file
+	OldSpans []Span `json:"oldSpans"`
+	NewSpans []Span `json:"newSpans"`
+}
+
+// linePart is a piece of a line that is either changed or unchanged
+type linePart struct {
+	Text    string
+	Changed bool
+}
+
+// tokenize splits a line into the units compared by the intraline mode
+func tokenize(line, mode string) []string {
+	var tokens []string
+	for start := 0; start < len(line); {
+		r, size := utf8.DecodeRuneInString(line[start:])
+		end := start + size
+		if mode == intralineWord {
+			// Runs of word characters and of whitespace form one token, anything else stands alone
+			class := runeClass(r)
+			for class != 0 && end < len(line) {
+				next, nextSize := utf8.DecodeRuneInString(line[end:])
+				if runeClass(next) != class {
+					break
+				}
+				end += nextSize
+			}
+		}
+		tokens = append(tokens, line[start:end])
+		start = end
+	}
+	return tokens
+}
+
+// runeClass groups runes into word characters (1), whitespace (2) and others (0)
+func runeClass(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 2
+	}
+	return 0
+}
+
+// intralineSpans returns the changed spans of a deleted line and the inserted
+// line replacing it. Both are nil if intraline highlighting is off or the lines
+// have nothing in common, then marking the whole lines says the same.
+func intralineSpans(oldLine, newLine string) ([]Span, []Span) {
+	mode := opts.Intraline
+	if mode == "" || mode == intralineNone || oldLine == newLine {
+		return nil, nil
+	}
+
+	a, b := tokenize(oldLine, mode), tokenize(newLine, mode)
+
+	oldSpans, newSpans, ok := tokenSpans(a, b)
+	if !ok {
+		oldSpans, newSpans = trimmedSpans(a, b)
+	}
+
+	if coversLine(oldSpans, len(oldLine)) && coversLine(newSpans, len(newLine)) {
+		return nil, nil
+	}
+	return oldSpans, newSpans
+}
+
+// coversLine reports whether spans mark all of a line of the given length as changed
+func coversLine(spans []Span, length int) bool {
+	return length == 0 || len(spans) == 1 && spans[0] == Span{Start: 0, End: length}
+}
+
+// tokenSpans diffs the tokens of two lines and merges changed tokens into spans.
+// It reports false if the lines differ in more than maxIntralineEdits tokens.
+func tokenSpans(a, b []string) ([]Span, []Span, bool) {
+	edits, ok := boundedMyersDiff(a, b, maxIntralineEdits)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var oldSpans, newSpans []Span
+	oldOffsets, newOffsets := tokenOffsets(a), tokenOffsets(b)
+	for _, e := range edits {
+		switch e.Op {
+		case editDelete:
+			oldSpans = addSpan(oldSpans, oldOffsets[e.A], oldOffsets[e.A+1])
+		case editInsert:
+			newSpans = addSpan(newSpans, newOffsets[e.B], newOffsets[e.B+1])
+		}
+	}
+	return oldSpans, newSpans, true
+}
+
+// trimmedSpans marks everything between the common prefix and suffix of two token lists as changed
+func trimmedSpans(a, b []string) ([]Span, []Span) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	oldOffsets, newOffsets := tokenOffsets(a), tokenOffsets(b)
+	return addSpan(nil, oldOffsets[prefix], oldOffsets[len(a)-suffix]),
+		addSpan(nil, newOffsets[prefix], newOffsets[len(b)-suffix])
+}
+
+// tokenOffsets returns the byte offset of each token and the line length at the end
+func tokenOffsets(tokens []string) []int {
+	offsets := make([]int, len(tokens)+1)
+	for i, token := range tokens {
+		offsets[i+1] = offsets[i] + len(token)
+	}
+	return offsets
+}
+
+// addSpan appends the range to spans, extending the last span if they touch
+func addSpan(spans []Span, start, end int) []Span {
+	if start == end {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].End == start {
+		spans[n-1].End = end
+		return spans
+	}
+	return append(spans, Span{Start: start, End: end})
+}
+
+// splitSpans cuts a line into changed and unchanged parts
+func splitSpans(line string, spans []Span) []linePart {
+	var parts []linePart
+	pos := 0
+	for _, span := range spans {
+		if span.Start > pos {
+			parts = append(parts, linePart{Text: line[pos:span.Start]})
+		}
+		parts = append(parts, linePart{Text: line[span.Start:span.End], Changed: true})
+		pos = span.End
+	}
+	if pos < len(line) {
+		parts = append(parts, linePart{Text: line[pos:]})
+	}
+	return parts
+}
+
+// intralineChanges returns the changed spans of the line pairs of a unified diff,
+// pairing deleted and inserted lines like the side-by-side view of the HTML report
+func intralineChanges(diff string) []LineChange {
+	var changes []LineChange
+	for _, row := range sideBySideRows(diff) {
+		if row.LeftSpans != nil || row.RightSpans != nil {
+			changes = append(changes, LineChange{
+				OldLine:  row.LeftNo,
+				NewLine:  row.RightNo,
+				OldSpans: nonNilSpans(row.LeftSpans),
+				NewSpans: nonNilSpans(row.RightSpans),
+			})
+		}
+	}
+	return changes
+}
+
+// nonNilSpans returns an empty slice for nil, so JSON lists are never null
+func nonNilSpans(spans []Span) []Span {
+	if spans == nil {
+		return []Span{}
+	}
+	return spans
+}
+
+// highlightSpans marks the changed parts of a line for the console by
+// reversing their colors, the line itself keeps the color set around it
+func highlightSpans(line string, spans []Span) string {
+	if !colorOutput || len(spans) == 0 {
+		return line
+	}
+
+	var b strings.Builder
+	for _, part := range splitSpans(line, spans) {
+		if part.Changed {
+			b.WriteString(ansiReverse + part.Text + ansiNoReverse)
+		} else {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
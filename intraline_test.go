@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize(`max_size = 10;  // größe`, intralineWord)
+	expected := []string{"max_size", " ", "=", " ", "10", ";", "  ", "/", "/", " ", "größe"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("word tokens = %q, expected %q", got, expected)
+	}
+
+	got = tokenize("aé1", intralineChar)
+	if !reflect.DeepEqual(got, []string{"a", "é", "1"}) {
+		t.Errorf("char tokens = %q", got)
+	}
+}
+
+func TestIntralineSpans(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	tests := []struct {
+		mode, old, new     string
+		oldSpans, newSpans []Span
+	}{
+		// The changed value only, not the whole line
+		{intralineWord, `{"name":"app","version":"1.2.3"}`, `{"name":"app","version":"1.2.4"}`,
+			[]Span{{29, 30}}, []Span{{29, 30}}},
+		// Words are replaced as a whole
+		{intralineWord, "timeout=30 seconds", "timeout=45 seconds", []Span{{8, 10}}, []Span{{8, 10}}},
+		{intralineChar, "timeout=30 seconds", "timeout=45 seconds", []Span{{8, 10}}, []Span{{8, 10}}},
+		{intralineChar, "color", "colour", nil, []Span{{4, 5}}},
+		// Insertions and deletions in the middle
+		{intralineWord, "a b c", "a c", []Span{{2, 4}}, nil},
+		// Nothing in common, the whole lines differ
+		{intralineWord, "foo", "bar", nil, nil},
+		{intralineNone, "timeout=30", "timeout=45", nil, nil},
+	}
+
+	for _, tt := range tests {
+		opts = defaultOptions()
+		opts.Intraline = tt.mode
+		oldSpans, newSpans := intralineSpans(tt.old, tt.new)
+		if !reflect.DeepEqual(oldSpans, tt.oldSpans) || !reflect.DeepEqual(newSpans, tt.newSpans) {
+			t.Errorf("%s %q → %q: spans %v %v, expected %v %v", tt.mode, tt.old, tt.new, oldSpans, newSpans, tt.oldSpans, tt.newSpans)
+		}
+	}
+}
+
+func TestIntralineSpansLongLine(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts = defaultOptions()
+
+	// Minified JSON of about 45 KB with two small changes gets exact spans
+	items := strings.Repeat(`{"id":1,"name":"item","tags":["a","b"]},`, 1100)
+	first := `{"version":"1.0","items":[` + items + `],"debug":false}`
+	second := `{"version":"1.1","items":[` + items + `],"debug":true}`
+	oldSpans, newSpans := intralineSpans(first, second)
+
+	end := strings.LastIndex(first, "false")
+	expectedOld := []Span{{14, 15}, {end, end + 5}}
+	expectedNew := []Span{{14, 15}, {end, end + 4}}
+	if !reflect.DeepEqual(oldSpans, expectedOld) || !reflect.DeepEqual(newSpans, expectedNew) {
+		t.Errorf("minified JSON spans %v %v, expected %v %v", oldSpans, newSpans, expectedOld, expectedNew)
+	}
+
+	// Too many changes for a token diff, the part between common prefix and suffix is marked
+	opts.Intraline = intralineChar
+	first = strings.Repeat("a", 100) + strings.Repeat("x", maxIntralineEdits) + strings.Repeat("b", 100)
+	second = strings.Repeat("a", 100) + strings.Repeat("y", maxIntralineEdits) + strings.Repeat("b", 100)
+	oldSpans, newSpans = intralineSpans(first, second)
+
+	expected := []Span{{100, 100 + maxIntralineEdits}}
+	if !reflect.DeepEqual(oldSpans, expected) || !reflect.DeepEqual(newSpans, expected) {
+		t.Errorf("rewritten line spans %v %v, expected %v", oldSpans, newSpans, expected)
+	}
+}
+
+func TestIntralineChanges(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+	opts = defaultOptions()
+
+	diff := generateDiff("one\nkey=old\nthree\nremoved line\n", "one\nkey=new\nthree\n", "file.properties")
+	changes := intralineChanges(diff)
+
+	expected := []LineChange{{OldLine: 2, NewLine: 2, OldSpans: []Span{{4, 7}}, NewSpans: []Span{{4, 7}}}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("changes = %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestIntralineChangesDashedContent(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+	opts = defaultOptions()
+
+	// The diff lines "--- old comment" and "+-- new comment" are content, not file headers
+	diff := generateDiff("SELECT 1;\n-- old comment\n", "SELECT 1;\n-- new comment\n", "query.sql")
+	changes := intralineChanges(diff)
+
+	expected := []LineChange{{OldLine: 2, NewLine: 2, OldSpans: []Span{{3, 6}}, NewSpans: []Span{{3, 6}}}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("changes = %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestIntralineInReports(t *testing.T) {
+	savedOpts, savedConsole, savedColor := opts, console, colorOutput
+	defer func() { opts, console, colorOutput = savedOpts, savedConsole, savedColor }()
+
+	dir := t.TempDir()
+	createTestZipIn(t, dir, "a.zip", map[string]string{"config.json": `{"debug":false,"level":3}` + "\n"})
+	createTestZipIn(t, dir, "b.zip", map[string]string{"config.json": `{"debug":true,"level":3}` + "\n"})
+
+	opts = defaultOptions()
+	result, err := compareZipFiles(filepath.Join(dir, "a.zip"), filepath.Join(dir, "b.zip"))
+	if err != nil {
+		t.Fatalf("compareZipFiles: %v", err)
+	}
+
+	// JSON: structured spans
+	jsonFile := filepath.Join(dir, "report.json")
+	if err := generateJSONReport(result, "a.zip", "b.zip", jsonFile); err != nil {
+		t.Fatalf("generateJSONReport: %v", err)
+	}
+	data, err := os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Different []struct {
+			Changes []LineChange `json:"changes"`
+		} `json:"different"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	expected := []LineChange{{OldLine: 1, NewLine: 1, OldSpans: []Span{{9, 14}}, NewSpans: []Span{{9, 13}}}}
+	if len(report.Different) != 1 || !reflect.DeepEqual(report.Different[0].Changes, expected) {
+		t.Errorf("JSON changes = %+v, expected %+v", report.Different, expected)
+	}
+
+	// HTML: changed words are wrapped in spans
+	htmlFile := filepath.Join(dir, "report.html")
+	if err := generateHTMLReport(result, "a.zip", "b.zip", htmlFile); err != nil {
+		t.Fatalf("generateHTMLReport: %v", err)
+	}
+	html, err := os.ReadFile(htmlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), `<span class="chg">false</span>`) || !strings.Contains(string(html), `<span class="chg">true</span>`) {
+		t.Errorf("HTML report should highlight the changed words, got:\n%s", html)
+	}
+
+	// Console: changed words are reversed within the colored lines
+	colorOutput = true
+	colored := colorDiffLines(strings.Split(strings.TrimSuffix(result.DiffDetails[0].Diff, "\n"), "\n"))
+	expectedLine := ansiRed + `-{"debug":` + ansiReverse + "false" + ansiNoReverse + `,"level":3}` + ansiReset
+	if colored[3] != expectedLine {
+		t.Errorf("colored deleted line = %q, expected %q", colored[3], expectedLine)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// jobs returns the number of workers, Jobs 0 means one per CPU
+func (o *Options) jobs() int {
+	if o.Jobs == 0 {
+		return runtime.NumCPU()
+	}
+	return o.Jobs
+}
+
+// runConcurrently runs the tasks in parallel and waits for all of them,
+// or runs them one after another if parallel is false
+func runConcurrently(parallel bool, tasks ...func()) {
+	if !parallel {
+		for _, task := range tasks {
+			task()
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(task func()) {
+			defer wg.Done()
+			task()
+		}(task)
+	}
+	wg.Wait()
+}
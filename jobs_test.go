@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParallelDirectoryComparisonIsDeterministic(t *testing.T) {
+	savedOpts, savedConsole := opts, console
+	defer func() { opts, console = savedOpts, savedConsole }()
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("pkg%02d", i)
+		createTestZipIn(t, dir1, name+"_v1.zip", map[string]string{"file.txt": "content", "changed.txt": "old"})
+		content := "old"
+		if i%3 == 0 {
+			content = "new"
+		}
+		createTestZipIn(t, dir2, name+"_v2.zip", map[string]string{"file.txt": "content", "changed.txt": content})
+	}
+
+	run := func(jobs int) (string, []byte) {
+		opts = defaultOptions()
+		opts.Jobs = jobs
+		var output bytes.Buffer
+		console = &output
+
+		outputDir := t.TempDir()
+		differences, err := compareDirectories(dir1, dir2, outputDir)
+		if err != nil {
+			t.Fatalf("compareDirectories with %d jobs failed: %v", jobs, err)
+		}
+		if !differences {
+			t.Errorf("Expected differences with %d jobs", jobs)
+		}
+
+		summary, err := os.ReadFile(filepath.Join(outputDir, "summary.xml"))
+		if err != nil {
+			t.Fatalf("Failed to read summary: %v", err)
+		}
+		return output.String(), summary
+	}
+
+	sequentialOutput, sequentialSummary := run(1)
+	parallelOutput, parallelSummary := run(4)
+
+	// Lines naming the output directory differ between the runs
+	stripOutputDir := func(output string) string {
+		var lines []string
+		for _, line := range strings.Split(output, "\n") {
+			if !strings.Contains(line, os.TempDir()) {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	if stripOutputDir(sequentialOutput) != stripOutputDir(parallelOutput) {
+		t.Errorf("Console output differs between 1 and 4 jobs:\n%s\n---\n%s", sequentialOutput, parallelOutput)
+	}
+	if !bytes.Equal(stripGenerated(sequentialSummary), stripGenerated(parallelSummary)) {
+		t.Errorf("Aggregated report differs between 1 and 4 jobs:\n%s\n---\n%s", sequentialSummary, parallelSummary)
+	}
+}
+
+// stripGenerated removes the generated timestamp attribute from a report
+func stripGenerated(report []byte) []byte {
+	start := bytes.Index(report, []byte(`generated="`))
+	if start < 0 {
+		return report
+	}
+	end := bytes.IndexByte(report[start+len(`generated="`):], '"')
+	return append(append([]byte(nil), report[:start]...), report[start+len(`generated="`)+end+1:]...)
+}
@@ -1,53 +1,73 @@
 package main
 
 import (
-	"archive/zip"
-	"crypto/sha256"
+	"bytes"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
-	"time"
 	"unicode/utf8"
 )
 
 type FileInfo struct {
 	Name     string
-	BaseName string // Name without commit code
+	BaseName string // Normalized path without commit codes, used as comparison key
 	Size     int64
 	Hash     string
-	Content  string // Store content for diff generation
+	Content  string // Text content, only held while a diff or similarity check needs it
 	IsBinary bool   // Track if file is binary
+
+	Index      int               // Position in the archive, see --order
+	Normalized bool              // Line filters removed lines before hashing
+	Metadata   map[string]string // Stored attributes of the entry, compared with --metadata
 }
 
 type DiffInfo struct {
-	FileName string `xml:"fileName"`
-	Diff     string `xml:"diff"`
-	IsBinary bool   `xml:"isBinary,attr"`
+	FileName string `xml:"fileName" json:"fileName"`
+	Diff     string `xml:"diff" json:"diff"`
+	IsBinary bool   `xml:"isBinary,attr" json:"isBinary"`
+
+	DiffSkipped bool `xml:"diffSkipped,attr,omitempty" json:"diffSkipped,omitempty"` // File exceeds the maximum diff size
+
+	Changes []LineChange `xml:"-" json:"changes,omitempty"` // Changed spans within the paired lines of Diff, see --intraline
 }
 
 type XMLReport struct {
-	XMLName      xml.Name   `xml:"zipComparison"`
-	Generated    string     `xml:"generated,attr"`
-	Zip1         string     `xml:"zip1,attr"`
-	Zip2         string     `xml:"zip2,attr"`
-	Identical    []string   `xml:"identical>file"`
-	Different    []DiffInfo `xml:"different>file"`
-	OnlyInFirst  []string   `xml:"onlyInFirst>file"`
-	OnlyInSecond []string   `xml:"onlyInSecond>file"`
-	Summary      Summary    `xml:"summary"`
+	XMLName      xml.Name     `xml:"zipComparison"`
+	Generated    string       `xml:"generated,attr,omitempty"`
+	Zip1         string       `xml:"zip1,attr"`
+	Zip2         string       `xml:"zip2,attr"`
+	Modes        []string     `xml:"modes>mode,omitempty"`
+	Identical    []string     `xml:"identical>file"`
+	Different    []DiffInfo   `xml:"different>file"`
+	OnlyInFirst  []string     `xml:"onlyInFirst>file"`
+	OnlyInSecond []string     `xml:"onlyInSecond>file"`
+	Renamed      []RenameInfo `xml:"renamed>file"`
+	Warnings     []string     `xml:"warnings>warning,omitempty"`
+	Normalized   []string     `xml:"normalized>file,omitempty"`
+
+	MetadataDiffers []MetadataDiff  `xml:"metadataDiffers>file,omitempty"`
+	ArchiveMetadata []MetadataField `xml:"archiveMetadata>field,omitempty"`
+
+	Summary Summary `xml:"summary"`
 }
 
 type Summary struct {
-	Total        int `xml:"total"`
-	Identical    int `xml:"identical"`
-	Different    int `xml:"different"`
-	OnlyInFirst  int `xml:"onlyInFirst"`
-	OnlyInSecond int `xml:"onlyInSecond"`
+	Total        int `xml:"total" json:"total"`
+	Identical    int `xml:"identical" json:"identical"`
+	Different    int `xml:"different" json:"different"`
+	OnlyInFirst  int `xml:"onlyInFirst" json:"onlyInFirst"`
+	OnlyInSecond int `xml:"onlyInSecond" json:"onlyInSecond"`
+	Renamed      int `xml:"renamed" json:"renamed"`
+	Ignored      int `xml:"ignored" json:"ignored"` // Not part of Total
+
+	MetadataDiffers int `xml:"metadataDiffers" json:"metadataDiffers"` // Also counted as identical or different
 }
 
 type ComparisonResult struct {
@@ -55,7 +75,15 @@ type ComparisonResult struct {
 	OnlyInSecond []string
 	Different    []string
 	Identical    []string
-	DiffDetails  []DiffInfo // Store detailed diff information
+	Renamed      []RenameInfo // Files only in one archive each, paired by content
+	DiffDetails  []DiffInfo   // Store detailed diff information
+	Ignored      []string     // Entries skipped by include/exclude patterns, in either archive
+	Normalized   []string     // Files whose content was changed by line filters, in either archive
+	Modes        []string     // Active normalization modes, see Options.activeModes
+
+	MetadataDiffers []MetadataDiff  // Files in both archives with different metadata, with --metadata
+	ArchiveMetadata []MetadataField // Differing archive level metadata like the comment, with --metadata
+	Warnings        []string        // Problems that did not stop the comparison, e.g. key collisions
 }
 
 type ZipPair struct {
@@ -64,84 +92,173 @@ type ZipPair struct {
 	Zip2Path string
 }
 
+// UnmatchedZips lists the ZIP files without a partner in the other directory
+type UnmatchedZips struct {
+	OnlyInDir1 []string
+	OnlyInDir2 []string
+}
+
+// any reports whether there is at least one unmatched ZIP file
+func (u UnmatchedZips) any() bool {
+	return len(u.OnlyInDir1) > 0 || len(u.OnlyInDir2) > 0
+}
+
+// DirectoryResult collects the outcome of a directory comparison for the aggregated report
+type DirectoryResult struct {
+	Dir1      string
+	Dir2      string
+	Pairs     []PairResult
+	Unmatched UnmatchedZips
+}
+
+// Status values of a PairResult
+const (
+	pairIdentical = "identical"
+	pairDifferent = "different"
+	pairError     = "error"
+)
+
+// PairResult is the outcome of comparing one ZIP pair in directory mode
+type PairResult struct {
+	BaseName string  `xml:"name,attr" json:"name"`
+	Status   string  `xml:"status,attr" json:"status"` // pairIdentical, pairDifferent or pairError
+	Zip1Path string  `xml:"zip1" json:"zip1"`
+	Zip2Path string  `xml:"zip2" json:"zip2"`
+	Error    string  `xml:"error,omitempty" json:"error,omitempty"`   // Error message if Status is pairError
+	Report   string  `xml:"report,omitempty" json:"report,omitempty"` // File name of the pair report inside the output directory, if written
+	Summary  Summary `xml:"summary" json:"summary"`                   // File counts, zero if the pair could not be compared
+}
+
 func main() {
-	if len(os.Args) < 3 || len(os.Args) > 4 {
-		fmt.Println("Usage:")
-		fmt.Println("  zipcompare <zip1> <zip2> [output.xml]           - Compare two ZIP files")
-		fmt.Println("  zipcompare <dir1> <dir2> [output_dir]           - Compare ZIP files in directories")
-		fmt.Println("    If output.xml is specified, results will be saved to XML file")
-		fmt.Println("    If output_dir is specified, XML reports will be saved there")
-		os.Exit(1)
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes a comparison for the given command line and returns the exit code
+func run(args []string) int {
+	args, err := parseCommandLine(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitIdentical
+	}
+	if err == errUsage {
+		return exitError
+	}
+	if err != nil {
+		log.Print(tr("error", err))
+		return exitError
 	}
 
-	path1 := os.Args[1]
-	path2 := os.Args[2]
-	var outputPath string
-	if len(os.Args) == 4 {
-		outputPath = os.Args[3]
+	if len(args) < 2 || len(args) > 3 {
+		printUsage(nil)
+		return exitError
+	}
+
+	path1 := args[0]
+	path2 := args[1]
+	outputPath := opts.OutputPath
+	if len(args) == 3 {
+		if outputPath != "" {
+			log.Print(tr("errorOutputTwice"))
+			return exitError
+		}
+		outputPath = args[2]
 	}
 
 	// Check if paths are directories or files
 	info1, err := os.Stat(path1)
 	if err != nil {
-		log.Fatalf("Error accessing %s: %v", path1, err)
+		log.Print(tr("errorAccess", path1, err))
+		return exitError
 	}
 
 	info2, err := os.Stat(path2)
 	if err != nil {
-		log.Fatalf("Error accessing %s: %v", path2, err)
+		log.Print(tr("errorAccess", path2, err))
+		return exitError
 	}
 
-	if info1.IsDir() && info2.IsDir() {
+	if info1.IsDir() && info2.IsDir() && !opts.Tree {
 		// Directory comparison mode
-		err = compareDirectories(path1, path2, outputPath)
+		differences, err := compareDirectories(path1, path2, outputPath)
 		if err != nil {
-			log.Fatalf("Error comparing directories: %v", err)
+			log.Print(tr("errorCompareDirs", err))
+			return exitError
 		}
-	} else if !info1.IsDir() && !info2.IsDir() {
-		// Single file comparison mode (existing functionality)
+		return exitStatus(differences)
+	} else if opts.Tree || !info1.IsDir() && !info2.IsDir() {
+		// Single comparison mode, directories are read as unpacked trees with --tree
 		result, err := compareZipFiles(path1, path2)
 		if err != nil {
-			log.Fatalf("Error comparing ZIP files: %v", err)
+			log.Print(tr("errorCompareZips", err))
+			return exitError
 		}
 
 		printResults(result)
 
 		if outputPath != "" {
-			err = generateXMLReport(result, path1, path2, outputPath)
+			err = writeReport(result, path1, path2, outputPath)
 			if err != nil {
-				log.Fatalf("Error generating XML report: %v", err)
+				log.Print(tr("reportFailed", strings.ToUpper(opts.Format), err))
+				return exitError
 			}
-			fmt.Printf("\n📄 XML-Report gespeichert: %s\n", outputPath)
+			fmt.Fprintf(console, plain("\n📄 %s\n"), tr("reportSaved", strings.ToUpper(opts.Format), outputPath))
 		}
-	} else {
-		log.Fatalf("Both paths must be either files or directories")
+		return exitStatus(hasDifferences(result))
 	}
+
+	log.Print(tr("errorPathKinds"))
+	return exitError
 }
 
-// extractBaseName removes commit codes from filenames
-// If filename ends with _<commitcode>, the commit code is removed
+// extractBaseName removes commit codes from filenames using the configured commit pattern.
+// The result is the "name" group followed by the "ext" group, if the pattern has one.
 func extractBaseName(filename string) string {
-	// Regex pattern to match commit codes at the end of filenames
-	// Assumes commit code is at least 6 characters long and alphanumeric
-	re := regexp.MustCompile(`^(.+)_[a-zA-Z0-9]{6,}(\.[^.]*)?$`)
+	if opts.NoNormalize {
+		return filename
+	}
+
+	re := opts.commitCodeRegexp()
 	matches := re.FindStringSubmatch(filename)
+	if matches == nil {
+		return filename
+	}
 
-	if len(matches) >= 2 {
-		baseName := matches[1]
-		if len(matches) >= 3 && matches[2] != "" {
-			baseName += matches[2] // Add file extension back
-		}
-		return baseName
+	baseName := matches[re.SubexpIndex("name")]
+	if baseName == "" {
+		return filename
+	}
+	if ext := re.SubexpIndex("ext"); ext >= 0 {
+		baseName += matches[ext] // Add file extension back
 	}
 
-	return filename
+	return baseName
 }
 
-// extractZipBaseName extracts the base name from ZIP file name (everything before last underscore)
+// normalizeEntryPath builds the comparison key for an archive entry.
+// The full in-archive path is kept and commit codes are removed from every path segment.
+func normalizeEntryPath(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = extractBaseName(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// extractZipBaseName extracts the base name from ZIP file name (everything before last underscore).
+// If a commit pattern is configured it is used instead of the last underscore.
 func extractZipBaseName(zipFileName string) string {
-	// Remove .zip extension
-	name := strings.TrimSuffix(zipFileName, ".zip")
+	// Remove archive extension like .zip or .tar.gz
+	name := strings.TrimSuffix(zipFileName, archiveSuffix(zipFileName))
+
+	if opts.NoNormalize {
+		return name
+	}
+	if opts.CommitPattern != "" {
+		return extractBaseName(name)
+	}
 
 	// Find last underscore
 	lastUnderscore := strings.LastIndex(name, "_")
@@ -152,111 +269,282 @@ func extractZipBaseName(zipFileName string) string {
 	return name[:lastUnderscore]
 }
 
-// findZipPairs finds matching ZIP files in two directories
-func findZipPairs(dir1, dir2 string) ([]ZipPair, error) {
-	// Read ZIP files from first directory
-	files1, err := filepath.Glob(filepath.Join(dir1, "*.zip"))
+// directoryArchiveSuffixes lists the archive types picked up in directory mode
+var directoryArchiveSuffixes = []string{".zip", ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tbz"}
+
+// findArchives returns the sorted paths of all archives directly in dir
+func findArchives(dir string) ([]string, error) {
+	var files []string
+	for _, suffix := range directoryArchiveSuffixes {
+		matches, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// findZipPairs finds matching archives (ZIP or tar) in two directories.
+// An archive may be paired with one of a different type, e.g. ZIP with tar.gz.
+// ZIP files without a partner in the other directory are returned as unmatched,
+// this includes further ZIP files with a base name that is already paired.
+func findZipPairs(dir1, dir2 string) ([]ZipPair, UnmatchedZips, error) {
+	var unmatched UnmatchedZips
+
+	// Read archives from first directory
+	files1, err := findArchives(dir1)
 	if err != nil {
-		return nil, fmt.Errorf("error reading directory %s: %w", dir1, err)
+		return nil, unmatched, err
 	}
 
-	// Read ZIP files from second directory
-	files2, err := filepath.Glob(filepath.Join(dir2, "*.zip"))
+	// Read archives from second directory
+	files2, err := findArchives(dir2)
 	if err != nil {
-		return nil, fmt.Errorf("error reading directory %s: %w", dir2, err)
+		return nil, unmatched, err
 	}
 
 	// Create map of base names to full paths for second directory
 	dir2Map := make(map[string]string)
 	for _, file2 := range files2 {
 		baseName := extractZipBaseName(filepath.Base(file2))
+		if _, exists := dir2Map[baseName]; exists {
+			unmatched.OnlyInDir2 = append(unmatched.OnlyInDir2, file2)
+			continue
+		}
 		dir2Map[baseName] = file2
 	}
 
 	// Find matching pairs
 	var pairs []ZipPair
+	paired := make(map[string]bool)
 	for _, file1 := range files1 {
 		baseName := extractZipBaseName(filepath.Base(file1))
-		if file2, exists := dir2Map[baseName]; exists {
-			pairs = append(pairs, ZipPair{
-				BaseName: baseName,
-				Zip1Path: file1,
-				Zip2Path: file2,
-			})
+		file2, exists := dir2Map[baseName]
+		if !exists || paired[baseName] {
+			unmatched.OnlyInDir1 = append(unmatched.OnlyInDir1, file1)
+			continue
+		}
+		paired[baseName] = true
+		pairs = append(pairs, ZipPair{
+			BaseName: baseName,
+			Zip1Path: file1,
+			Zip2Path: file2,
+		})
+	}
+
+	for baseName, file2 := range dir2Map {
+		if !paired[baseName] {
+			unmatched.OnlyInDir2 = append(unmatched.OnlyInDir2, file2)
 		}
 	}
+	sort.Strings(unmatched.OnlyInDir2)
 
-	return pairs, nil
+	return pairs, unmatched, nil
 }
 
-// compareDirectories compares all matching ZIP files in two directories
-func compareDirectories(dir1, dir2, outputDir string) error {
-	fmt.Printf("🔍 Suche nach ZIP-Dateien in Verzeichnissen...\n")
-	fmt.Printf("   Verzeichnis 1: %s\n", dir1)
-	fmt.Printf("   Verzeichnis 2: %s\n", dir2)
-	fmt.Println()
+// pairOutcome is the result of comparing one ZIP pair, with its console output
+type pairOutcome struct {
+	result      PairResult
+	output      bytes.Buffer
+	differences bool
+	failed      bool // The pair could not be compared or its report not be written
+}
 
-	pairs, err := findZipPairs(dir1, dir2)
+// comparePair compares one ZIP pair and writes its report if outputDir is set.
+// Console output is buffered in the outcome, so pairs can be compared concurrently.
+func comparePair(pair ZipPair, index, total int, outputDir string) *pairOutcome {
+	outcome := &pairOutcome{
+		result: PairResult{
+			BaseName: pair.BaseName,
+			Zip1Path: pair.Zip1Path,
+			Zip2Path: pair.Zip2Path,
+		},
+	}
+	out := &outcome.output
+	pairResult := &outcome.result
+
+	fmt.Fprintf(out, plain("📊 %s\n"), tr("comparing", index+1, total, pair.BaseName))
+
+	result, err := compareZipFiles(pair.Zip1Path, pair.Zip2Path)
 	if err != nil {
-		return err
+		fmt.Fprintf(out, plain("   ❌ %s\n"), paint(ansiRed, tr("compareFailed", err)))
+		outcome.failed = true
+		pairResult.Status = pairError
+		pairResult.Error = err.Error()
+		return outcome
 	}
 
-	if len(pairs) == 0 {
-		fmt.Println("❌ Keine passenden ZIP-Dateien gefunden!")
-		return nil
+	pairResult.Summary = buildSummary(result)
+	pairResult.Status = pairIdentical
+	if hasDifferences(result) {
+		outcome.differences = true
+		pairResult.Status = pairDifferent
 	}
 
-	fmt.Printf("✅ %d passende ZIP-Paare gefunden:\n", len(pairs))
-	for _, pair := range pairs {
-		fmt.Printf("   • %s\n", pair.BaseName)
+	// Print summary for this pair
+	summary := pairResult.Summary
+	fmt.Fprintf(out, "   %s\n", plain(tr("pairSummary",
+		summary.Total, summary.Identical, summary.Different, summary.OnlyInFirst, summary.OnlyInSecond, summary.Renamed)))
+	if opts.ShowDiff && len(result.DiffDetails) > 0 {
+		fmt.Fprintln(out)
+		printDiffs(out, result.DiffDetails)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(out, plain("   ❗ %s\n"), paint(ansiYellow, warning))
 	}
-	fmt.Println()
 
-	// Create output directory if specified
+	// Generate report if output directory is specified
 	if outputDir != "" {
-		err := os.MkdirAll(outputDir, 0755)
+		reportFileName := fmt.Sprintf("%s_comparison.%s", pair.BaseName, opts.Format)
+		reportPath := filepath.Join(outputDir, reportFileName)
+
+		err = writeReport(result, pair.Zip1Path, pair.Zip2Path, reportPath)
 		if err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+			fmt.Fprintf(out, plain("   ❌ %s\n"), paint(ansiRed, tr("reportFailed", strings.ToUpper(opts.Format), err)))
+			outcome.failed = true
+			pairResult.Status = pairError
+			pairResult.Error = err.Error()
+		} else {
+			fmt.Fprintf(out, plain("   📄 %s\n"), tr("pairReport", strings.ToUpper(opts.Format), reportFileName))
+			pairResult.Report = reportFileName
 		}
 	}
+	fmt.Fprintln(out)
 
-	// Process each pair
-	for i, pair := range pairs {
-		fmt.Printf("📊 Vergleiche %d/%d: %s\n", i+1, len(pairs), pair.BaseName)
+	return outcome
+}
 
-		result, err := compareZipFiles(pair.Zip1Path, pair.Zip2Path)
-		if err != nil {
-			fmt.Printf("   ❌ Fehler beim Vergleichen: %v\n", err)
-			continue
+// comparePairs compares the pairs with up to opts.Jobs workers. The console
+// output of each pair is printed as soon as all pairs before it are done, so
+// output and returned outcomes are in pair order regardless of completion order.
+func comparePairs(pairs []ZipPair, outputDir string) []*pairOutcome {
+	outcomes := make([]*pairOutcome, len(pairs))
+	if len(pairs) == 0 {
+		return outcomes
+	}
+
+	indices := make(chan int)
+	done := make(chan int)
+	for w := 0; w < min(opts.jobs(), len(pairs)); w++ {
+		go func() {
+			for i := range indices {
+				outcomes[i] = comparePair(pairs[i], i, len(pairs), outputDir)
+				done <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range pairs {
+			indices <- i
+		}
+		close(indices)
+	}()
+
+	finished := make([]bool, len(pairs))
+	next := 0
+	for range pairs {
+		finished[<-done] = true
+		for next < len(pairs) && finished[next] {
+			console.Write(outcomes[next].output.Bytes())
+			next++
 		}
+	}
 
-		// Print summary for this pair
-		totalFiles := len(result.Identical) + len(result.Different) + len(result.OnlyInFirst) + len(result.OnlyInSecond)
-		fmt.Printf("   📁 Dateien: %d | ✅ Identisch: %d | ⚠️  Unterschiedlich: %d | 📋 Nur in 1: %d | 📋 Nur in 2: %d\n",
-			totalFiles, len(result.Identical), len(result.Different), len(result.OnlyInFirst), len(result.OnlyInSecond))
+	return outcomes
+}
 
-		// Generate XML report if output directory is specified
-		if outputDir != "" {
-			xmlFileName := fmt.Sprintf("%s_comparison.xml", pair.BaseName)
-			xmlPath := filepath.Join(outputDir, xmlFileName)
+// compareDirectories compares all matching ZIP files in two directories
+// and reports whether any differences were found. Pairs that fail to compare
+// do not stop the run, they are reported in the returned error afterwards.
+func compareDirectories(dir1, dir2, outputDir string) (bool, error) {
+	fmt.Fprintf(console, plain("🔍 %s\n"), tr("searching"))
+	fmt.Fprintf(console, "   %s: %s\n", tr("directory1"), dir1)
+	fmt.Fprintf(console, "   %s: %s\n", tr("directory2"), dir2)
+	fmt.Fprintln(console)
+
+	pairs, unmatched, err := findZipPairs(dir1, dir2)
+	if err != nil {
+		return false, err
+	}
 
-			err = generateXMLReport(result, pair.Zip1Path, pair.Zip2Path, xmlPath)
-			if err != nil {
-				fmt.Printf("   ❌ Fehler beim Erstellen des XML-Reports: %v\n", err)
-			} else {
-				fmt.Printf("   📄 XML-Report: %s\n", xmlFileName)
-			}
+	if len(pairs) == 0 {
+		fmt.Fprintf(console, plain("❌ %s\n"), paint(ansiRed, tr("noPairs")))
+	} else {
+		fmt.Fprintf(console, plain("✅ %s\n"), tr("pairsFound", len(pairs)))
+		for _, pair := range pairs {
+			fmt.Fprintf(console, plain("   • %s\n"), pair.BaseName)
+		}
+	}
+	fmt.Fprintln(console)
+
+	if len(unmatched.OnlyInDir1) > 0 {
+		fmt.Fprintf(console, plain("📁 %s (%d):\n"), tr("onlyInDir1"), len(unmatched.OnlyInDir1))
+		for _, zipPath := range unmatched.OnlyInDir1 {
+			fmt.Fprintf(console, plain("   • %s\n"), filepath.Base(zipPath))
+		}
+		fmt.Fprintln(console)
+	}
+	if len(unmatched.OnlyInDir2) > 0 {
+		fmt.Fprintf(console, plain("📁 %s (%d):\n"), tr("onlyInDir2"), len(unmatched.OnlyInDir2))
+		for _, zipPath := range unmatched.OnlyInDir2 {
+			fmt.Fprintf(console, plain("   • %s\n"), filepath.Base(zipPath))
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
+	}
+
+	if len(pairs) == 0 && !unmatched.any() {
+		return false, nil
 	}
 
+	// Create output directory if specified
 	if outputDir != "" {
-		fmt.Printf("🎉 Alle Vergleiche abgeschlossen! XML-Reports gespeichert in: %s\n", outputDir)
+		err := os.MkdirAll(outputDir, 0755)
+		if err != nil {
+			return false, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	// Process each pair, ZIP files without partner count as differences
+	differences := unmatched.any()
+	failed := 0
+	var pairResults []PairResult
+	for _, outcome := range comparePairs(pairs, outputDir) {
+		differences = differences || outcome.differences
+		if outcome.failed {
+			failed++
+		}
+		pairResults = append(pairResults, outcome.result)
+	}
+
+	// Aggregated report linking all pair reports
+	if outputDir != "" {
+		directoryResult := &DirectoryResult{
+			Dir1:      dir1,
+			Dir2:      dir2,
+			Pairs:     pairResults,
+			Unmatched: unmatched,
+		}
+		directoryReport := directoryReports[opts.Format]
+		summaryPath := filepath.Join(outputDir, directoryReport.fileName)
+		if err := directoryReport.write(directoryResult, summaryPath); err != nil {
+			return differences, err
+		}
+		fmt.Fprintf(console, plain("📑 %s\n"), tr("overview", summaryPath))
+	}
+
+	if outputDir != "" {
+		fmt.Fprintf(console, plain("🎉 %s\n"), tr("allDoneReports", strings.ToUpper(opts.Format), outputDir))
 	} else {
-		fmt.Printf("🎉 Alle Vergleiche abgeschlossen!\n")
+		fmt.Fprintf(console, plain("🎉 %s\n"), tr("allDone"))
 	}
 
-	return nil
+	if failed > 0 {
+		return differences, fmt.Errorf("%d of %d ZIP pairs could not be compared or reported", failed, len(pairs))
+	}
+
+	return differences, nil
 }
 
 // isBinaryContent checks if content is binary
@@ -276,127 +564,190 @@ func isBinaryContent(content []byte) bool {
 	return false
 }
 
-// generateDiff creates a simple line-by-line diff
-func generateDiff(content1, content2, fileName string) string {
-	if content1 == content2 {
-		return ""
+// readZipContents reads an archive (ZIP or tar, see openArchive) and collects file information keyed by normalized path.
+// Entries are hashed while streaming, their content is not kept, see loadTextContents.
+// Entries rejected by filter are skipped before hashing, entries whose keys collide are reported as warnings.
+// Top level ZIP entries whose keys are in unchanged are identified by their CRC32 instead of being hashed.
+func readZipContents(zipPath string, filter *entryFilter, unchanged map[string]bool) (*entryCollector, error) {
+	reader, err := openArchive(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", zipPath, err)
 	}
+	defer reader.Close()
 
-	lines1 := strings.Split(content1, "\n")
-	lines2 := strings.Split(content2, "\n")
+	collector := newEntryCollector(filepath.Base(zipPath), filter)
+	collector.comment = reader.Comment()
+	collector.unchanged = unchanged
+	if err := collector.readArchive(reader, "", "", 0); err != nil {
+		return nil, err
+	}
 
-	var diff strings.Builder
-	diff.WriteString(fmt.Sprintf("--- %s (ZIP 1)\n", fileName))
-	diff.WriteString(fmt.Sprintf("+++ %s (ZIP 2)\n", fileName))
+	return collector, nil
+}
 
-	maxLines := len(lines1)
-	if len(lines2) > maxLines {
-		maxLines = len(lines2)
-	}
+// diffBatchSize bounds the text content held in memory for diffs. The differing
+// files are read in batches of up to this many bytes of both archives, and each
+// batch is diffed and released before the next one is read. A pair larger than
+// this, at most twice the maximum diff size, is read on its own.
+const diffBatchSize = 64 << 20
 
-	for i := 0; i < maxLines; i++ {
-		var line1, line2 string
-		if i < len(lines1) {
-			line1 = lines1[i]
-		}
-		if i < len(lines2) {
-			line2 = lines2[i]
-		}
+// isDiffCandidate reports whether the text content of a file is read for a diff or similarity check
+func isDiffCandidate(file FileInfo) bool {
+	return !file.IsBinary && withinDiffSize(file.Size)
+}
 
-		if line1 != line2 {
-			if i < len(lines1) {
-				diff.WriteString(fmt.Sprintf("-%s\n", line1))
-			}
-			if i < len(lines2) {
-				diff.WriteString(fmt.Sprintf("+%s\n", line2))
-			}
+// diffBatches groups the text files that differ and fit the maximum diff size
+// into batches of at most diffBatchSize bytes of both archives, in result order
+func diffBatches(result *ComparisonResult, files1, files2 map[string]FileInfo) [][]string {
+	var batches [][]string
+	var batch []string
+	var size int64
+	for _, baseName := range result.Different {
+		file1, file2 := files1[baseName], files2[baseName]
+		if !isDiffCandidate(file1) || !isDiffCandidate(file2) {
+			continue
+		}
+		if len(batch) > 0 && size+file1.Size+file2.Size > diffBatchSize {
+			batches = append(batches, batch)
+			batch, size = nil, 0
 		}
+		batch = append(batch, baseName)
+		size += file1.Size + file2.Size
 	}
-
-	return diff.String()
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
 }
 
-// readZipContents reads a ZIP file and returns file information
-func readZipContents(zipPath string) (map[string]FileInfo, error) {
-	reader, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open ZIP file %s: %w", zipPath, err)
+// renameCandidates returns the text files in only one archive that near match
+// rename detection compares, keyed by normalized path with the entry name as value
+func renameCandidates(result *ComparisonResult, files1, files2 map[string]FileInfo) (map[string]string, map[string]string) {
+	wanted1 := make(map[string]string)
+	wanted2 := make(map[string]string)
+	if opts.NoRenames || opts.RenameThreshold <= 0 {
+		return wanted1, wanted2
 	}
-	defer reader.Close()
-
-	files := make(map[string]FileInfo)
 
-	for _, file := range reader.File {
-		// Skip directories
-		if file.FileInfo().IsDir() {
-			continue
+	for _, baseName := range result.OnlyInFirst {
+		if isDiffCandidate(files1[baseName]) {
+			wanted1[baseName] = files1[baseName].Name
+		}
+	}
+	for _, baseName := range result.OnlyInSecond {
+		if isDiffCandidate(files2[baseName]) {
+			wanted2[baseName] = files2[baseName].Name
 		}
+	}
+	return wanted1, wanted2
+}
 
-		fileReader, err := file.Open()
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s in ZIP: %w", file.Name, err)
+// generateDiffs reads the text files that differ batch by batch, see diffBatches,
+// and returns their diffs keyed by normalized path. The content of each file is
+// released as soon as its diff is generated.
+func generateDiffs(zip1Path, zip2Path string, filter *entryFilter, result *ComparisonResult, files1, files2 map[string]FileInfo) (map[string]string, error) {
+	diffs := make(map[string]string)
+	for _, batch := range diffBatches(result, files1, files2) {
+		wanted1 := make(map[string]string, len(batch))
+		wanted2 := make(map[string]string, len(batch))
+		for _, baseName := range batch {
+			wanted1[baseName] = files1[baseName].Name
+			wanted2[baseName] = files2[baseName].Name
+		}
+		if err := loadBothTextContents(zip1Path, zip2Path, filter, wanted1, wanted2, files1, files2); err != nil {
+			return nil, err
 		}
 
-		// Read file content into memory
-		content, err := io.ReadAll(fileReader)
-		if err != nil {
-			fileReader.Close()
-			return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+		for _, baseName := range batch {
+			file1, file2 := files1[baseName], files2[baseName]
+			diffs[baseName] = generateDiff(file1.Content, file2.Content, baseName)
+			file1.Content, file2.Content = "", ""
+			files1[baseName], files2[baseName] = file1, file2
 		}
-		fileReader.Close()
+	}
+	return diffs, nil
+}
 
-		// Calculate hash of file content
-		hash := sha256.Sum256(content)
+// loadBothTextContents reads the wanted text contents of both archives, concurrently with --jobs
+func loadBothTextContents(zip1Path, zip2Path string, filter *entryFilter, wanted1, wanted2 map[string]string, files1, files2 map[string]FileInfo) error {
+	var err1, err2 error
+	runConcurrently(opts.jobs() > 1,
+		func() { err1 = loadTextContents(zip1Path, filter, wanted1, files1) },
+		func() { err2 = loadTextContents(zip2Path, filter, wanted2, files2) })
+	if err1 != nil {
+		return fmt.Errorf("error reading first ZIP file: %w", err1)
+	}
+	if err2 != nil {
+		return fmt.Errorf("error reading second ZIP file: %w", err2)
+	}
+	return nil
+}
 
-		baseName := extractBaseName(filepath.Base(file.Name))
+// loadTextContents reads the archive again and stores the text content of the
+// wanted entries in files
+func loadTextContents(zipPath string, filter *entryFilter, wanted map[string]string, files map[string]FileInfo) error {
+	if len(wanted) == 0 {
+		return nil
+	}
 
-		// Check if content is binary
-		isBinary := isBinaryContent(content)
-		var contentStr string
-		if !isBinary {
-			contentStr = string(content)
-		}
+	reader, err := openArchive(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", zipPath, err)
+	}
+	defer reader.Close()
 
-		fileInfo := FileInfo{
-			Name:     file.Name,
-			BaseName: baseName,
-			Size:     int64(len(content)),
-			Hash:     fmt.Sprintf("%x", hash),
-			Content:  contentStr,
-			IsBinary: isBinary,
-		}
-
-		// Use full path + baseName as key to handle duplicates
-		key := baseName
-		if existingFile, exists := files[key]; exists {
-			// If we have a duplicate base name, prefer the one without commit code
-			if len(existingFile.Name) > len(file.Name) {
-				files[key] = fileInfo
-			}
-		} else {
-			files[key] = fileInfo
-		}
+	collector := newEntryCollector(filepath.Base(zipPath), filter)
+	collector.wanted = wanted
+	collector.contents = make(map[string]string)
+	if err := collector.readArchive(reader, "", "", 0); err != nil {
+		return err
 	}
 
-	return files, nil
-} // compareZipFiles compares two ZIP files and returns the comparison result
+	for key, text := range collector.contents {
+		fileInfo := files[key]
+		fileInfo.Content = text
+		files[key] = fileInfo
+	}
+	return nil
+}
+
+// compareZipFiles compares two ZIP files and returns the comparison result
 func compareZipFiles(zip1Path, zip2Path string) (*ComparisonResult, error) {
-	files1, err := readZipContents(zip1Path)
+	filter, err := comparisonFilter(zip1Path, zip2Path)
 	if err != nil {
-		return nil, fmt.Errorf("error reading first ZIP file: %w", err)
+		return nil, err
 	}
 
-	files2, err := readZipContents(zip2Path)
-	if err != nil {
-		return nil, fmt.Errorf("error reading second ZIP file: %w", err)
+	// Fast path: entries with equal central directory checksums are not decompressed
+	var unchanged map[string]bool
+	if !opts.Strict {
+		unchanged = unchangedEntries(zip1Path, zip2Path)
 	}
 
+	// Both archives are read concurrently with --jobs
+	var contents1, contents2 *entryCollector
+	var err1, err2 error
+	runConcurrently(opts.jobs() > 1,
+		func() { contents1, err1 = readZipContents(zip1Path, filter, unchanged) },
+		func() { contents2, err2 = readZipContents(zip2Path, filter, unchanged) })
+	if err1 != nil {
+		return nil, fmt.Errorf("error reading first ZIP file: %w", err1)
+	}
+	if err2 != nil {
+		return nil, fmt.Errorf("error reading second ZIP file: %w", err2)
+	}
+	files1, files2 := contents1.files, contents2.files
+
 	result := &ComparisonResult{
 		OnlyInFirst:  []string{},
 		OnlyInSecond: []string{},
 		Different:    []string{},
 		Identical:    []string{},
+		Renamed:      []RenameInfo{},
 		DiffDetails:  []DiffInfo{},
+		Warnings:     append(contents1.warnings, contents2.warnings...),
+		Ignored:      mergeNames(contents1.ignored, contents2.ignored),
+		Modes:        opts.activeModes(),
 	}
 
 	// Check files in first ZIP
@@ -406,19 +757,6 @@ func compareZipFiles(zip1Path, zip2Path string) (*ComparisonResult, error) {
 				result.Identical = append(result.Identical, baseName)
 			} else {
 				result.Different = append(result.Different, baseName)
-
-				// Generate diff for non-binary files
-				var diff string
-				isBinary := file1.IsBinary || file2.IsBinary
-				if !isBinary {
-					diff = generateDiff(file1.Content, file2.Content, baseName)
-				}
-
-				result.DiffDetails = append(result.DiffDetails, DiffInfo{
-					FileName: baseName,
-					Diff:     diff,
-					IsBinary: isBinary,
-				})
 			}
 		} else {
 			result.OnlyInFirst = append(result.OnlyInFirst, baseName)
@@ -432,81 +770,241 @@ func compareZipFiles(zip1Path, zip2Path string) (*ComparisonResult, error) {
 		}
 	}
 
+	sortResult(result, files1, files2)
+
+	// Text content is only read for files that need a diff or a similarity check
+	diffs, err := generateDiffs(zip1Path, zip2Path, filter, result, files1, files2)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, baseName := range result.Different {
+		file1, file2 := files1[baseName], files2[baseName]
+
+		// Generate diff for non-binary files
+		var diff string
+		isBinary := file1.IsBinary || file2.IsBinary
+		diffSkipped := !isBinary && !(withinDiffSize(file1.Size) && withinDiffSize(file2.Size))
+		if diffSkipped {
+			result.Warnings = append(result.Warnings, tr("warnDiffSkipped", baseName, opts.MaxDiffSize))
+		} else if !isBinary {
+			diff = diffs[baseName]
+		}
+
+		result.DiffDetails = append(result.DiffDetails, DiffInfo{
+			FileName:    baseName,
+			Diff:        diff,
+			IsBinary:    isBinary,
+			DiffSkipped: diffSkipped,
+			Changes:     intralineChanges(diff),
+		})
+	}
+
+	result.Normalized = mergeNames(normalizedNames(files1), normalizedNames(files2))
+
+	if opts.CompareMetadata {
+		compareMetadata(result, files1, files2)
+		result.ArchiveMetadata = diffMetadata(
+			map[string]string{"comment": contents1.comment},
+			map[string]string{"comment": contents2.comment})
+	}
+
+	wanted1, wanted2 := renameCandidates(result, files1, files2)
+	if err := loadBothTextContents(zip1Path, zip2Path, filter, wanted1, wanted2, files1, files2); err != nil {
+		return nil, err
+	}
+	detectRenames(result, files1, files2)
+	sortResult(result, files1, files2)
+
 	return result, nil
 }
 
+// hasDifferences reports whether the compared archives differ in any way
+func hasDifferences(result *ComparisonResult) bool {
+	return len(result.Different) > 0 || len(result.OnlyInFirst) > 0 || len(result.OnlyInSecond) > 0 ||
+		len(result.Renamed) > 0 || len(result.MetadataDiffers) > 0 || len(result.ArchiveMetadata) > 0
+}
+
 // printResults prints the comparison results in a readable format
 func printResults(result *ComparisonResult) {
-	fmt.Println("=== ZIP-Datei Vergleich ===")
-	fmt.Println()
+	fmt.Fprintln(console, paint(ansiBold, tr("title")))
+	fmt.Fprintln(console)
+
+	if len(result.Modes) > 0 {
+		fmt.Fprintf(console, plain("⚙️  %s: %s\n\n"), tr("modes"), strings.Join(result.Modes, ", "))
+	}
 
 	if len(result.Identical) > 0 {
-		fmt.Printf("✅ Identische Dateien (%d):\n", len(result.Identical))
+		fmt.Fprintf(console, plain("✅ %s\n"), paint(ansiGreen, fmt.Sprintf("%s (%d):", tr("identicalFiles"), len(result.Identical))))
 		for _, file := range result.Identical {
-			fmt.Printf("  • %s\n", file)
+			fmt.Fprintf(console, plain("  • %s\n"), file)
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
 	}
 
 	if len(result.Different) > 0 {
-		fmt.Printf("⚠️  Unterschiedliche Dateien (%d):\n", len(result.Different))
+		fmt.Fprintf(console, plain("⚠️  %s\n"), paint(ansiYellow, fmt.Sprintf("%s (%d):", tr("differentFiles"), len(result.Different))))
 		for _, file := range result.Different {
-			fmt.Printf("  • %s\n", file)
+			fmt.Fprintf(console, plain("  • %s\n"), file)
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
 	}
 
 	if len(result.OnlyInFirst) > 0 {
-		fmt.Printf("📁 Nur in der ersten ZIP-Datei (%d):\n", len(result.OnlyInFirst))
+		fmt.Fprintf(console, plain("📁 %s\n"), paint(ansiRed, fmt.Sprintf("%s (%d):", tr("onlyInFirst"), len(result.OnlyInFirst))))
 		for _, file := range result.OnlyInFirst {
-			fmt.Printf("  • %s\n", file)
+			fmt.Fprintf(console, plain("  • %s\n"), file)
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
 	}
 
 	if len(result.OnlyInSecond) > 0 {
-		fmt.Printf("📁 Nur in der zweiten ZIP-Datei (%d):\n", len(result.OnlyInSecond))
+		fmt.Fprintf(console, plain("📁 %s\n"), paint(ansiRed, fmt.Sprintf("%s (%d):", tr("onlyInSecond"), len(result.OnlyInSecond))))
 		for _, file := range result.OnlyInSecond {
-			fmt.Printf("  • %s\n", file)
+			fmt.Fprintf(console, plain("  • %s\n"), file)
+		}
+		fmt.Fprintln(console)
+	}
+
+	if len(result.Renamed) > 0 {
+		fmt.Fprintf(console, plain("🔀 %s\n"), paint(ansiCyan, fmt.Sprintf("%s (%d):", tr("renamed"), len(result.Renamed))))
+		for _, rename := range result.Renamed {
+			if rename.Similarity < 1 {
+				fmt.Fprintf(console, plain("  • %s → %s (%s)\n"), rename.From, rename.To, tr("similar", rename.Similarity*100))
+			} else {
+				fmt.Fprintf(console, plain("  • %s → %s\n"), rename.From, rename.To)
+			}
+		}
+		fmt.Fprintln(console)
+	}
+
+	if len(result.MetadataDiffers) > 0 || len(result.ArchiveMetadata) > 0 {
+		fmt.Fprintf(console, plain("🏷️  %s\n"), paint(ansiYellow, fmt.Sprintf("%s (%d):", tr("metadataDiffers"), len(result.MetadataDiffers))))
+		for _, field := range result.ArchiveMetadata {
+			fmt.Fprintf(console, plain("  • %s: %s %q → %q\n"), tr("archive"), field.Name, field.First, field.Second)
+		}
+		for _, file := range result.MetadataDiffers {
+			fmt.Fprintf(console, plain("  • %s\n"), file.FileName)
+			for _, field := range file.Fields {
+				fmt.Fprintf(console, plain("      %s: %s → %s\n"), field.Name, field.First, field.Second)
+			}
+		}
+		fmt.Fprintln(console)
+	}
+
+	if len(result.Normalized) > 0 {
+		fmt.Fprintf(console, plain("🧹 %s (%d):\n"), tr("normalized"), len(result.Normalized))
+		for _, file := range result.Normalized {
+			fmt.Fprintf(console, plain("  • %s\n"), file)
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
+	}
+
+	if opts.ShowDiff && len(result.DiffDetails) > 0 {
+		printDiffs(console, result.DiffDetails)
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Fprintf(console, plain("❗ %s\n"), paint(ansiYellow, fmt.Sprintf("%s (%d):", tr("warnings"), len(result.Warnings))))
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(console, plain("  • %s\n"), warning)
+		}
+		fmt.Fprintln(console)
 	}
 
 	// Summary
-	totalFiles := len(result.Identical) + len(result.Different) + len(result.OnlyInFirst) + len(result.OnlyInSecond)
-	fmt.Printf("📊 Zusammenfassung:\n")
-	fmt.Printf("  Gesamt Dateien: %d\n", totalFiles)
-	fmt.Printf("  Identisch: %d\n", len(result.Identical))
-	fmt.Printf("  Unterschiedlich: %d\n", len(result.Different))
-	fmt.Printf("  Nur in ZIP 1: %d\n", len(result.OnlyInFirst))
-	fmt.Printf("  Nur in ZIP 2: %d\n", len(result.OnlyInSecond))
-
-	if len(result.Different) == 0 && len(result.OnlyInFirst) == 0 && len(result.OnlyInSecond) == 0 {
-		fmt.Println("\n🎉 Die ZIP-Dateien sind identisch!")
+	summary := buildSummary(result)
+	fmt.Fprintf(console, plain("📊 %s\n"), paint(ansiBold, tr("summary")+":"))
+	fmt.Fprintf(console, "  %s: %d\n", tr("totalFiles"), summary.Total)
+	fmt.Fprintf(console, "  %s: %d\n", tr("identical"), summary.Identical)
+	fmt.Fprintf(console, "  %s: %d\n", tr("different"), summary.Different)
+	fmt.Fprintf(console, "  %s: %d\n", tr("onlyInZip1"), summary.OnlyInFirst)
+	fmt.Fprintf(console, "  %s: %d\n", tr("onlyInZip2"), summary.OnlyInSecond)
+	fmt.Fprintf(console, "  %s: %d\n", tr("renamed"), summary.Renamed)
+	if opts.CompareMetadata {
+		fmt.Fprintf(console, "  %s: %d\n", tr("metadataDiffers"), summary.MetadataDiffers)
+	}
+	if summary.Ignored > 0 {
+		fmt.Fprintf(console, "  %s: %d\n", tr("ignored"), summary.Ignored)
+	}
+
+	if !hasDifferences(result) {
+		fmt.Fprintf(console, plain("\n🎉 %s\n"), paint(ansiGreen, tr("archivesIdentical")))
 	} else {
-		fmt.Println("\n⚠️  Die ZIP-Dateien unterscheiden sich.")
+		fmt.Fprintf(console, plain("\n⚠️  %s\n"), paint(ansiYellow, tr("archivesDiffer")))
 	}
 }
 
+// buildSummary counts the files in each category of the comparison result
+func buildSummary(result *ComparisonResult) Summary {
+	return Summary{
+		Total:        len(result.Identical) + len(result.Different) + len(result.OnlyInFirst) + len(result.OnlyInSecond) + len(result.Renamed),
+		Identical:    len(result.Identical),
+		Different:    len(result.Different),
+		OnlyInFirst:  len(result.OnlyInFirst),
+		OnlyInSecond: len(result.OnlyInSecond),
+		Renamed:      len(result.Renamed),
+		Ignored:      len(result.Ignored),
+
+		MetadataDiffers: len(result.MetadataDiffers),
+	}
+}
+
+// mergeNames returns the sorted union of two name lists
+func mergeNames(names1, names2 []string) []string {
+	seen := make(map[string]bool)
+	merged := []string{}
+	for _, name := range append(append([]string(nil), names1...), names2...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// reportFormats maps each supported --format value to its report writer
+var reportFormats = map[string]func(result *ComparisonResult, zip1Path, zip2Path, outputPath string) error{
+	"xml":  generateXMLReport,
+	"json": generateJSONReport,
+	"html": generateHTMLReport,
+}
+
+// reportFormatNames returns the supported --format values in alphabetical order
+func reportFormatNames() []string {
+	names := make([]string, 0, len(reportFormats))
+	for name := range reportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeReport writes the comparison result in the configured report format
+func writeReport(result *ComparisonResult, zip1Path, zip2Path, outputPath string) error {
+	return reportFormats[opts.Format](result, zip1Path, zip2Path, outputPath)
+}
+
 // generateXMLReport creates an XML report with detailed comparison results
 func generateXMLReport(result *ComparisonResult, zip1Path, zip2Path, outputPath string) error {
-	totalFiles := len(result.Identical) + len(result.Different) + len(result.OnlyInFirst) + len(result.OnlyInSecond)
-
 	report := XMLReport{
-		Generated:    time.Now().Format(time.RFC3339),
+		Generated:    reportTimestamp(),
 		Zip1:         zip1Path,
 		Zip2:         zip2Path,
+		Modes:        result.Modes,
 		Identical:    result.Identical,
 		Different:    result.DiffDetails,
 		OnlyInFirst:  result.OnlyInFirst,
 		OnlyInSecond: result.OnlyInSecond,
-		Summary: Summary{
-			Total:        totalFiles,
-			Identical:    len(result.Identical),
-			Different:    len(result.Different),
-			OnlyInFirst:  len(result.OnlyInFirst),
-			OnlyInSecond: len(result.OnlyInSecond),
-		},
+		Renamed:      result.Renamed,
+		Warnings:     result.Warnings,
+		Normalized:   result.Normalized,
+
+		MetadataDiffers: result.MetadataDiffers,
+		ArchiveMetadata: result.ArchiveMetadata,
+
+		Summary: buildSummary(result),
 	}
 
 	// Create XML content
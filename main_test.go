@@ -34,6 +34,19 @@ func createTestZip(files map[string]string) (string, error) {
 	return tmpFile.Name(), nil
 }
 
+// createTestZipIn creates a test ZIP file with the given name in dir
+func createTestZipIn(t *testing.T, dir, name string, files map[string]string) {
+	t.Helper()
+
+	zipPath, err := createTestZip(files)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	if err := os.Rename(zipPath, filepath.Join(dir, name)); err != nil {
+		t.Fatalf("Failed to move test ZIP: %v", err)
+	}
+}
+
 func TestExtractBaseName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -346,7 +359,7 @@ func TestFindZipPairs(t *testing.T) {
 	os.Rename(zip5, filepath.Join(dir2, "unmatched.zip"))
 
 	// Find pairs
-	pairs, err := findZipPairs(dir1, dir2)
+	pairs, unmatched, err := findZipPairs(dir1, dir2)
 	if err != nil {
 		t.Fatalf("findZipPairs failed: %v", err)
 	}
@@ -374,4 +387,184 @@ func TestFindZipPairs(t *testing.T) {
 	if !foundRelease {
 		t.Error("Expected to find 'release' pair")
 	}
+
+	// unmatched.zip has no partner in the first directory
+	if len(unmatched.OnlyInDir1) != 0 {
+		t.Errorf("Expected no ZIP only in dir1, got %v", unmatched.OnlyInDir1)
+	}
+	if len(unmatched.OnlyInDir2) != 1 || filepath.Base(unmatched.OnlyInDir2[0]) != "unmatched.zip" {
+		t.Errorf("Expected unmatched.zip only in dir2, got %v", unmatched.OnlyInDir2)
+	}
+}
+
+func TestNormalizeEntryPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"file.txt", "file.txt"},
+		{"module-a/config.xml", "module-a/config.xml"},
+		{"module-a/config_abc123.xml", "module-a/config.xml"},
+		{"lib_def456/util_789abc.js", "lib/util.js"},
+		{"./docs/readme.md", "docs/readme.md"},
+		{"win\\path\\file_a1b2c3.txt", "win/path/file.txt"},
+	}
+
+	for _, test := range tests {
+		result := normalizeEntryPath(test.input)
+		if result != test.expected {
+			t.Errorf("normalizeEntryPath(%s) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestCompareZipsKeepsDirectories(t *testing.T) {
+	files1 := map[string]string{
+		"module-a/config.xml": "config a",
+		"module-b/config.xml": "config b",
+	}
+
+	files2 := map[string]string{
+		"module-a/config.xml": "config a",
+		"module-b/config.xml": "config b changed",
+	}
+
+	zip1, err := createTestZip(files1)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	defer os.Remove(zip1)
+
+	zip2, err := createTestZip(files2)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+
+	if len(result.Identical) != 1 || result.Identical[0] != "module-a/config.xml" {
+		t.Errorf("Expected module-a/config.xml to be identical, got %v", result.Identical)
+	}
+
+	if len(result.Different) != 1 || result.Different[0] != "module-b/config.xml" {
+		t.Errorf("Expected module-b/config.xml to be different, got %v", result.Different)
+	}
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func TestCompareZipsReportsKeyCollisions(t *testing.T) {
+	files := map[string]string{
+		"dir/file.txt":        "plain",
+		"dir/file_abc123.txt": "with commit code",
+	}
+
+	zip1, err := createTestZip(files)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	defer os.Remove(zip1)
+
+	zip2, err := createTestZip(map[string]string{"dir/file.txt": "plain"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "dir/file_abc123.txt") {
+		t.Errorf("Expected one collision warning for dir/file_abc123.txt, got %v", result.Warnings)
+	}
+
+	// The entry without commit code is the one compared
+	if len(result.Identical) != 1 {
+		t.Errorf("Expected 1 identical file, got %d", len(result.Identical))
+	}
+}
+
+func TestExtractBaseNameCustomPattern(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.CommitPattern = `^(?P<name>.+)-g[0-9a-f]{7,}(?P<ext>\.[^.]*)?$`
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"app-g1a2b3c4.jar", "app.jar"},
+		{"tool-1.2-g1a2b3c4d", "tool-1.2"},
+		{"setup_windows.exe", "setup_windows.exe"},
+		{"notes_release.txt", "notes_release.txt"},
+		{"file_abc123.txt", "file_abc123.txt"},
+	}
+
+	for _, test := range tests {
+		result := extractBaseName(test.input)
+		if result != test.expected {
+			t.Errorf("extractBaseName(%s) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+
+	if result := extractZipBaseName("release-g1a2b3c4.zip"); result != "release" {
+		t.Errorf("extractZipBaseName(release-g1a2b3c4.zip) = %s; want release", result)
+	}
+}
+
+func TestExtractBaseNameNoNormalize(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.NoNormalize = true
+
+	if result := extractBaseName("file_abc123.txt"); result != "file_abc123.txt" {
+		t.Errorf("extractBaseName(file_abc123.txt) = %s; want file_abc123.txt", result)
+	}
+	if result := extractZipBaseName("package_v1.zip"); result != "package_v1" {
+		t.Errorf("extractZipBaseName(package_v1.zip) = %s; want package_v1", result)
+	}
+}
+
+func TestInvalidCommitPattern(t *testing.T) {
+	for _, pattern := range []string{`^(.+)_[a-z]+$`, `^(?P<name>.+`} {
+		o := defaultOptions()
+		o.CommitPattern = pattern
+		if err := o.compile(); err == nil {
+			t.Errorf("compile should reject commit pattern %q", pattern)
+		}
+	}
+}
+
+func TestFindZipPairsDuplicateBaseNames(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+
+	createTestZipIn(t, dir1, "package_v1.zip", map[string]string{"test.txt": "content"})
+	createTestZipIn(t, dir1, "package_v2.zip", map[string]string{"test.txt": "content"})
+	createTestZipIn(t, dir2, "package_v3.zip", map[string]string{"test.txt": "content"})
+
+	pairs, unmatched, err := findZipPairs(dir1, dir2)
+	if err != nil {
+		t.Fatalf("findZipPairs failed: %v", err)
+	}
+
+	if len(pairs) != 1 {
+		t.Errorf("Expected 1 pair, got %d", len(pairs))
+	}
+	if len(unmatched.OnlyInDir1) != 1 || filepath.Base(unmatched.OnlyInDir1[0]) != "package_v2.zip" {
+		t.Errorf("Expected package_v2.zip to be unmatched, got %v", unmatched.OnlyInDir1)
+	}
 }
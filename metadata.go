@@ -0,0 +1,77 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MetadataField is a stored attribute with different values in the two archives
+type MetadataField struct {
+	Name   string `xml:"name,attr" json:"name"`
+	First  string `xml:"first,attr" json:"first"`
+	Second string `xml:"second,attr" json:"second"`
+}
+
+// MetadataDiff lists the differing metadata fields of a file present in both archives
+type MetadataDiff struct {
+	FileName string          `xml:"name,attr" json:"fileName"`
+	Fields   []MetadataField `xml:"field" json:"fields"`
+}
+
+// formatModTime formats modification times uniformly for all archive formats
+func formatModTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// zipMetadata returns the comparable fields of a ZIP file header
+func zipMetadata(header *zip.FileHeader) map[string]string {
+	method := fmt.Sprintf("%d", header.Method)
+	switch header.Method {
+	case zip.Store:
+		method = "store"
+	case zip.Deflate:
+		method = "deflate"
+	}
+
+	return map[string]string{
+		"modified":      formatModTime(header.Modified),
+		"mode":          header.Mode().String(),
+		"externalAttrs": fmt.Sprintf("0x%08x", header.ExternalAttrs),
+		"method":        method,
+		"comment":       header.Comment,
+		"extra":         fmt.Sprintf("%x", header.Extra),
+	}
+}
+
+// diffMetadata returns the fields present in both maps with different values,
+// sorted by name. Fields only one archive format provides are not compared.
+func diffMetadata(metadata1, metadata2 map[string]string) []MetadataField {
+	var fields []MetadataField
+	for name, value1 := range metadata1 {
+		if value2, ok := metadata2[name]; ok && value1 != value2 {
+			fields = append(fields, MetadataField{Name: name, First: value1, Second: value2})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// compareMetadata records the files present in both archives whose metadata differs
+func compareMetadata(result *ComparisonResult, files1, files2 map[string]FileInfo) {
+	var names []string
+	for name := range files1 {
+		if _, exists := files2[name]; exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result.MetadataDiffers = []MetadataDiff{}
+	for _, name := range names {
+		if fields := diffMetadata(files1[name].Metadata, files2[name].Metadata); len(fields) > 0 {
+			result.MetadataDiffers = append(result.MetadataDiffers, MetadataDiff{FileName: name, Fields: fields})
+		}
+	}
+}
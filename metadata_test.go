@@ -0,0 +1,102 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"testing"
+	"time"
+)
+
+// createMetadataTestZip writes a ZIP with one file.txt entry using the given header settings
+func createMetadataTestZip(t *testing.T, modified time.Time, mode os.FileMode, method uint16, comment string) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "meta*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	header := &zip.FileHeader{Name: "file.txt", Method: method, Modified: modified}
+	header.SetMode(mode)
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	writer.Write([]byte("content"))
+	zipWriter.SetComment(comment)
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+
+	return file.Name()
+}
+
+func TestCompareMetadata(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	modified := time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC)
+	zip1 := createMetadataTestZip(t, modified, 0644, zip.Deflate, "build 1")
+	zip2 := createMetadataTestZip(t, modified.Add(time.Hour), 0755, zip.Store, "build 2")
+	zip3 := createMetadataTestZip(t, modified, 0644, zip.Deflate, "build 1")
+
+	// Without --metadata only the content counts
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if hasDifferences(result) {
+		t.Errorf("Expected no differences without metadata comparison, got %+v", result.MetadataDiffers)
+	}
+
+	opts.CompareMetadata = true
+	result, err = compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if !hasDifferences(result) {
+		t.Error("Expected metadata differences to count as differences")
+	}
+	if len(result.Identical) != 1 {
+		t.Errorf("Content should still be identical, got %v", result.Identical)
+	}
+	if len(result.MetadataDiffers) != 1 || result.MetadataDiffers[0].FileName != "file.txt" {
+		t.Fatalf("Expected file.txt to differ in metadata, got %+v", result.MetadataDiffers)
+	}
+
+	fields := make(map[string]MetadataField)
+	for _, field := range result.MetadataDiffers[0].Fields {
+		fields[field.Name] = field
+	}
+	for _, name := range []string{"modified", "mode", "externalAttrs", "method"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("Expected field %s to differ, got %+v", name, result.MetadataDiffers[0].Fields)
+		}
+	}
+	if field := fields["method"]; field.First != "deflate" || field.Second != "store" {
+		t.Errorf("Unexpected method values: %+v", field)
+	}
+	if len(result.ArchiveMetadata) != 1 || result.ArchiveMetadata[0].Name != "comment" {
+		t.Errorf("Expected archive comment to differ, got %+v", result.ArchiveMetadata)
+	}
+
+	result, err = compareZipFiles(zip1, zip3)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if hasDifferences(result) {
+		t.Errorf("Expected identical metadata, got %+v %+v", result.MetadataDiffers, result.ArchiveMetadata)
+	}
+}
+
+func TestDiffMetadataSkipsFieldsOfOneSide(t *testing.T) {
+	fields := diffMetadata(
+		map[string]string{"mode": "-rw-r--r--", "method": "deflate"},
+		map[string]string{"mode": "-rwxr-xr-x"})
+
+	if len(fields) != 1 || fields[0].Name != "mode" {
+		t.Errorf("Expected only mode to be compared, got %+v", fields)
+	}
+}
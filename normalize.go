@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LineFilter removes the lines matching any of Patterns from the text files matching Files
+type LineFilter struct {
+	Files    string   `json:"files"`    // Glob pattern as for --include, empty for all files
+	Patterns []string `json:"patterns"` // Regular expressions matched against each line without line break
+}
+
+// compiledLineFilter is a LineFilter with compiled patterns
+type compiledLineFilter struct {
+	files    string
+	patterns []*regexp.Regexp
+}
+
+// compileLineFilters compiles the global ignore patterns and the per file line filters
+func compileLineFilters(global []string, perFile []LineFilter) ([]compiledLineFilter, error) {
+	filters := append([]LineFilter{{Patterns: global}}, perFile...)
+
+	var compiled []compiledLineFilter
+	for _, filter := range filters {
+		if len(filter.Patterns) == 0 {
+			continue
+		}
+		if err := validateGlob(filter.Files); err != nil {
+			return nil, err
+		}
+
+		c := compiledLineFilter{files: filter.Files}
+		for _, pattern := range filter.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid line filter %q: %w", pattern, err)
+			}
+			c.patterns = append(c.patterns, re)
+		}
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+// normalizeContent removes the lines matched by the line filters that apply to
+// a text file, names are its stored and normalized path. It reports whether
+// any line was removed.
+func normalizeContent(content string, names ...string) (string, bool) {
+	patterns := lineFilterPatterns(names...)
+	if len(patterns) == 0 {
+		return content, false
+	}
+
+	var normalized strings.Builder
+	removed := false
+	for _, line := range splitLines(content) {
+		if matchesAnyLine(patterns, strings.TrimRight(line, "\r\n")) {
+			removed = true
+			continue
+		}
+		normalized.WriteString(line)
+	}
+
+	return normalized.String(), removed
+}
+
+// lineFilterPatterns returns the line filter patterns that apply to a file
+func lineFilterPatterns(names ...string) []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, filter := range opts.lineFilters {
+		if filter.files == "" || matchAnyGlob([]string{filter.files}, names) {
+			patterns = append(patterns, filter.patterns...)
+		}
+	}
+	return patterns
+}
+
+// matchesAnyLine reports whether one of the patterns matches the line
+func matchesAnyLine(patterns []*regexp.Regexp, line string) bool {
+	for _, re := range patterns {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizedNames returns the keys of the files changed by line filters
+func normalizedNames(files map[string]FileInfo) []string {
+	var names []string
+	for key, file := range files {
+		if file.Normalized {
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// utf8BOM is the byte order mark removed by --ignore-bom
+const utf8BOM = "\uFEFF"
+
+// ignoresLineDifferences reports whether any mode makes different lines compare equal
+func (o *Options) ignoresLineDifferences() bool {
+	return o.IgnoreEOL || o.IgnoreTrailingSpace || o.IgnoreAllSpace || o.IgnoreCase
+}
+
+// activeModes returns the names of the active normalization modes for reports
+func (o *Options) activeModes() []string {
+	var modes []string
+	for _, mode := range []struct {
+		name   string
+		active bool
+	}{
+		{"ignore-eol", o.IgnoreEOL},
+		{"ignore-bom", o.IgnoreBOM},
+		{"ignore-trailing-space", o.IgnoreTrailingSpace},
+		{"ignore-all-space", o.IgnoreAllSpace},
+		{"ignore-case", o.IgnoreCase},
+		{"ignore-lines", len(o.lineFilters) > 0},
+	} {
+		if mode.active {
+			modes = append(modes, mode.name)
+		}
+	}
+	return modes
+}
+
+// comparisonKey returns the form of a line that is compared in place of the line,
+// with the differences ignored by the active modes removed
+func comparisonKey(line string) string {
+	if !opts.ignoresLineDifferences() {
+		return line
+	}
+
+	// The line ending, including a "\r" of CRLF, is kept apart so trailing
+	// space before it can be trimmed
+	body := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	eol := line[len(body):]
+	if opts.IgnoreEOL {
+		eol = ""
+	}
+
+	if opts.IgnoreAllSpace {
+		body = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, body)
+	} else if opts.IgnoreTrailingSpace {
+		body = strings.TrimRight(body, " \t")
+	}
+
+	if opts.IgnoreCase {
+		body = strings.ToLower(body)
+	}
+
+	return body + eol
+}
+
+// comparisonKeys maps lines to their comparison keys
+func comparisonKeys(lines []string) []string {
+	if !opts.ignoresLineDifferences() {
+		return lines
+	}
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = comparisonKey(line)
+	}
+	return keys
+}
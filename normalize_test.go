@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.IgnoreLines = []string{`^Built: `}
+	opts.LineFilters = []LineFilter{{Files: "*.properties", Patterns: []string{`^build\.number=`}}}
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	content, normalized := normalizeContent("Built: 2026-01-01\r\nname=app\r\n", "README.txt")
+	if content != "name=app\r\n" || !normalized {
+		t.Errorf("Expected global filter to remove the header, got %q (%v)", content, normalized)
+	}
+
+	content, normalized = normalizeContent("build.number=42\nname=app\n", "README.txt")
+	if content != "build.number=42\nname=app\n" || normalized {
+		t.Errorf("Per file filter must not apply to README.txt, got %q (%v)", content, normalized)
+	}
+
+	content, normalized = normalizeContent("build.number=42\nname=app\n", "conf/app.properties")
+	if content != "name=app\n" || !normalized {
+		t.Errorf("Expected per file filter to remove the build number, got %q (%v)", content, normalized)
+	}
+}
+
+func TestLineFiltersMakeFilesIdentical(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.IgnoreLines = []string{`^Built: `}
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	result := compareTestZips(t,
+		map[string]string{"info.txt": "Built: 2026-01-01\nversion 1\n", "other.txt": "same\n"},
+		map[string]string{"info.txt": "Built: 2026-02-03\nversion 1\n", "other.txt": "same\n"})
+
+	if hasDifferences(result) {
+		t.Errorf("Expected info.txt to be identical after filtering, got %v", result.Different)
+	}
+	if !reflect.DeepEqual(result.Normalized, []string{"info.txt"}) {
+		t.Errorf("Expected info.txt to be reported as normalized, got %v", result.Normalized)
+	}
+}
+
+func TestInvalidLineFilter(t *testing.T) {
+	o := defaultOptions()
+	o.LineFilters = []LineFilter{{Files: "*.txt", Patterns: []string{"(unclosed"}}}
+	if err := o.compile(); err == nil {
+		t.Error("Expected error for invalid line filter regex")
+	}
+}
+
+func TestWhitespaceModes(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	files1 := map[string]string{
+		"eol.txt":      "line one\r\nline two\r\n",
+		"bom.txt":      "\uFEFFtext\n",
+		"trailing.txt": "value  \nother\t\n",
+		"crlf.txt":     "value  \r\nother\t\r\n",
+		"spaces.txt":   "a = b + c\n",
+		"case.txt":     "Hello World\n",
+	}
+	files2 := map[string]string{
+		"eol.txt":      "line one\nline two\n",
+		"bom.txt":      "text\n",
+		"trailing.txt": "value\nother\n",
+		"crlf.txt":     "value\r\nother\r\n",
+		"spaces.txt":   "a=b+c\n",
+		"case.txt":     "hello world\n",
+	}
+
+	tests := []struct {
+		name string
+		set  func(o *Options)
+		file string
+	}{
+		{"ignore-eol", func(o *Options) { o.IgnoreEOL = true }, "eol.txt"},
+		{"ignore-bom", func(o *Options) { o.IgnoreBOM = true }, "bom.txt"},
+		{"ignore-trailing-space", func(o *Options) { o.IgnoreTrailingSpace = true }, "trailing.txt"},
+		{"ignore-trailing-space", func(o *Options) { o.IgnoreTrailingSpace = true }, "crlf.txt"},
+		{"ignore-all-space", func(o *Options) { o.IgnoreAllSpace = true }, "spaces.txt"},
+		{"ignore-case", func(o *Options) { o.IgnoreCase = true }, "case.txt"},
+	}
+
+	for _, test := range tests {
+		opts = defaultOptions()
+		result := compareTestZips(t, files1, files2)
+		if len(result.Different) != len(files1) {
+			t.Fatalf("Without modes all files should differ, got %v", result.Different)
+		}
+
+		test.set(&opts)
+		result = compareTestZips(t, files1, files2)
+		found := false
+		for _, name := range result.Identical {
+			found = found || name == test.file
+		}
+		if !found {
+			t.Errorf("%s: expected %s to be identical, identical files: %v", test.name, test.file, result.Identical)
+		}
+		if !reflect.DeepEqual(result.Modes, []string{test.name}) {
+			t.Errorf("%s: expected the mode to be recorded, got %v", test.name, result.Modes)
+		}
+	}
+}
+
+func TestDiffIgnoresWhitespaceChanges(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.IgnoreTrailingSpace = true
+	diff := generateDiff("a \nb\nc \n", "a\nB\nc\n", "file.txt")
+
+	// Only the changed line b is reported, the lines keep their original form
+	expected := "--- a/file.txt\n+++ b/file.txt\n@@ -1,3 +1,3 @@\n a \n-b\n+B\n c \n"
+	if diff != expected {
+		t.Errorf("Unexpected diff:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultCommitPattern matches commit codes of at least 6 alphanumeric characters
+// after the last underscore, e.g. file_abc123.txt
+const defaultCommitPattern = `^(?P<name>.+)_[a-zA-Z0-9]{6,}(?P<ext>\.[^.]*)?$`
+
+var defaultCommitRegexp = regexp.MustCompile(defaultCommitPattern)
+
+// Options holds the settings that influence how archives are compared and reported
+type Options struct {
+	ContextLines  int    // Number of unchanged lines around each diff hunk
+	ShowDiff      bool   // Print the diffs of different files to the console
+	DiffLines     int    // Maximum diff lines printed per file with ShowDiff, 0 for all
+	Intraline     string // Highlighting of changes within lines, intralineWord, intralineChar or intralineNone
+	CommitPattern string // Regex with a "name" and optional "ext" group, empty for the default
+	NoNormalize   bool   // Compare file names exactly as stored, without removing commit codes
+	Format        string // Report format, one of reportFormats
+	OutputPath    string // Report file, or report directory in directory mode
+	Quiet         bool   // Suppress console output
+	Plain         bool   // Console output without emojis and colors, for logs and legacy consoles
+	Color         string // When to color console output, colorAuto, colorAlways or colorNever
+	Order         string // Order of files in console output and reports, orderPath or orderArchive
+	NoTimestamp   bool   // Leave the generation time out of reports
+	Jobs          int    // Number of ZIP pairs compared in parallel, 0 for one per CPU
+	Lang          string // Language of console output and HTML reports, empty to follow LC_ALL/LANG
+
+	NoRenames       bool    // Report renamed and moved files as only in one archive
+	RenameThreshold float64 // Minimum line similarity for near match renames, 0 pairs identical content only
+
+	Include []string // Glob patterns of files to compare, empty compares all
+	Exclude []string // Glob patterns of entries to ignore, in addition to .zipcompareignore files
+
+	IgnoreLines []string     // Regexes of lines removed from all text files before comparing
+	LineFilters []LineFilter // Line filters for the text files matching a glob, from the config file
+
+	IgnoreEOL           bool // Treat CRLF and LF line endings as equal
+	IgnoreBOM           bool // Remove a leading UTF-8 byte order mark from text files
+	IgnoreTrailingSpace bool // Ignore spaces and tabs at the end of lines
+	IgnoreAllSpace      bool // Ignore all whitespace within lines
+	IgnoreCase          bool // Compare lines case-insensitively
+
+	MaxDiffSize     int64 // Largest file in bytes read into memory for a diff, 0 for no limit
+	Strict          bool  // Hash all entries, even those with equal CRC32 and size in both ZIP files
+	CompareMetadata bool  // Also compare stored metadata like modification times and permissions
+
+	Tree      bool // Read directory arguments as unpacked trees instead of folders of archives
+	Recursive bool // Compare the entries of nested archives instead of the archives as a whole
+	MaxDepth  int  // Maximum nesting level opened in recursive mode
+
+	commitRegexp *regexp.Regexp       // Compiled CommitPattern, set by compile
+	lineFilters  []compiledLineFilter // Compiled IgnoreLines and LineFilters, set by compile
+	lang         string               // Resolved message catalog language, set by compile
+}
+
+// opts holds the active options, main fills it from the config file and command line
+var opts = defaultOptions()
+
+// defaultOptions returns the options used when nothing is configured
+func defaultOptions() Options {
+	return Options{
+		ContextLines: 3,
+		Format:       "xml",
+		Order:        orderPath,
+		Color:        colorAuto,
+		Intraline:    intralineWord,
+		MaxDepth:     5,
+		MaxDiffSize:  10 << 20,
+		Jobs:         1,
+	}
+}
+
+// compile validates the options and prepares derived values like compiled patterns
+func (o *Options) compile() error {
+	if o.ContextLines < 0 {
+		return fmt.Errorf("context lines must not be negative: %d", o.ContextLines)
+	}
+
+	if o.DiffLines < 0 {
+		return fmt.Errorf("diff lines must not be negative: %d", o.DiffLines)
+	}
+
+	if o.Intraline != intralineWord && o.Intraline != intralineChar && o.Intraline != intralineNone {
+		return fmt.Errorf("unsupported intraline mode: %s (use %s, %s or %s)", o.Intraline, intralineWord, intralineChar, intralineNone)
+	}
+
+	if _, ok := reportFormats[o.Format]; !ok {
+		return fmt.Errorf("unsupported report format: %s", o.Format)
+	}
+
+	if o.MaxDepth < 1 {
+		return fmt.Errorf("max depth must be at least 1: %d", o.MaxDepth)
+	}
+
+	if o.Order != orderPath && o.Order != orderArchive {
+		return fmt.Errorf("unsupported order: %s (use %s or %s)", o.Order, orderPath, orderArchive)
+	}
+
+	o.lang = environmentLanguage()
+	if o.Lang != "" {
+		o.lang = languageTag(o.Lang)
+		if _, ok := messages[o.lang]; !ok {
+			return fmt.Errorf("unsupported language: %s (use %s)", o.Lang, strings.Join(supportedLanguages(), " or "))
+		}
+	}
+
+	if o.Color != colorAuto && o.Color != colorAlways && o.Color != colorNever {
+		return fmt.Errorf("unsupported color mode: %s (use %s, %s or %s)", o.Color, colorAuto, colorAlways, colorNever)
+	}
+
+	if o.Jobs < 0 {
+		return fmt.Errorf("jobs must not be negative: %d", o.Jobs)
+	}
+
+	if o.MaxDiffSize < 0 {
+		return fmt.Errorf("max diff size must not be negative: %d", o.MaxDiffSize)
+	}
+
+	if o.RenameThreshold < 0 || o.RenameThreshold > 1 {
+		return fmt.Errorf("rename threshold must be between 0 and 1: %g", o.RenameThreshold)
+	}
+
+	for _, pattern := range append(append([]string(nil), o.Include...), o.Exclude...) {
+		if err := validateGlob(pattern); err != nil {
+			return err
+		}
+	}
+
+	lineFilters, err := compileLineFilters(o.IgnoreLines, o.LineFilters)
+	if err != nil {
+		return err
+	}
+	o.lineFilters = lineFilters
+
+	o.commitRegexp = nil
+	if o.CommitPattern != "" {
+		re, err := regexp.Compile(o.CommitPattern)
+		if err != nil {
+			return fmt.Errorf("invalid commit pattern: %w", err)
+		}
+		if re.SubexpIndex("name") < 0 {
+			return fmt.Errorf("commit pattern %q must contain a named group (?P<name>...)", o.CommitPattern)
+		}
+		o.commitRegexp = re
+	}
+
+	return nil
+}
+
+// commitCodeRegexp returns the pattern used to remove commit codes from names
+func (o *Options) commitCodeRegexp() *regexp.Regexp {
+	if o.commitRegexp != nil {
+		return o.commitRegexp
+	}
+	return defaultCommitRegexp
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Values of Options.Order
+const (
+	orderPath    = "path"    // Sorted by normalized path
+	orderArchive = "archive" // In the order the entries are stored in the archive
+)
+
+// sortNames sorts names of entries of files according to opts.Order
+func sortNames(names []string, files map[string]FileInfo) {
+	sort.SliceStable(names, func(i, j int) bool {
+		return entryLess(names[i], names[j], files)
+	})
+}
+
+// entryLess orders two entries of files, by archive position with --order archive
+// and by path otherwise
+func entryLess(name1, name2 string, files map[string]FileInfo) bool {
+	if opts.Order == orderArchive {
+		index1, index2 := files[name1].Index, files[name2].Index
+		if index1 != index2 {
+			return index1 < index2
+		}
+	}
+	return name1 < name2
+}
+
+// sortResult puts all lists of a comparison result into a deterministic order.
+// Files of the first archive determine the order, except for those only in the second one.
+func sortResult(result *ComparisonResult, files1, files2 map[string]FileInfo) {
+	sortNames(result.Identical, files1)
+	sortNames(result.Different, files1)
+	sortNames(result.OnlyInFirst, files1)
+	sortNames(result.OnlyInSecond, files2)
+
+	sort.SliceStable(result.Renamed, func(i, j int) bool {
+		return entryLess(result.Renamed[i].From, result.Renamed[j].From, files1)
+	})
+	sort.SliceStable(result.MetadataDiffers, func(i, j int) bool {
+		return entryLess(result.MetadataDiffers[i].FileName, result.MetadataDiffers[j].FileName, files1)
+	})
+}
+
+// reportTimestamp returns the generation time written to reports. It is empty
+// with --no-timestamp and taken from SOURCE_DATE_EPOCH if that is set, so
+// reports can be reproduced byte by byte.
+func reportTimestamp() string {
+	if opts.NoTimestamp {
+		return ""
+	}
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC().Format(time.RFC3339)
+	}
+	return time.Now().Format(time.RFC3339)
+}
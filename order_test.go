@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// createOrderedTestZip writes a ZIP with the entries in the given order
+func createOrderedTestZip(t *testing.T, dir, name string, entries []string, content string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(dir, name)
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	for _, entry := range entries {
+		writer, err := zipWriter.Create(entry)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", entry, err)
+		}
+		writer.Write([]byte(entry + content))
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	return zipPath
+}
+
+func TestResultOrder(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	dir := t.TempDir()
+	entries := []string{"zeta.txt", "alpha.txt", "mid/beta.txt", "gamma.txt"}
+	zip1 := createOrderedTestZip(t, dir, "a.zip", entries, " old")
+	zip2 := createOrderedTestZip(t, dir, "b.zip", entries, " new")
+
+	for i := 0; i < 5; i++ {
+		result, err := compareZipFiles(zip1, zip2)
+		if err != nil {
+			t.Fatalf("compareZipFiles failed: %v", err)
+		}
+		expected := []string{"alpha.txt", "gamma.txt", "mid/beta.txt", "zeta.txt"}
+		if !reflect.DeepEqual(result.Different, expected) {
+			t.Fatalf("Expected path order %v, got %v", expected, result.Different)
+		}
+		for j, detail := range result.DiffDetails {
+			if detail.FileName != expected[j] {
+				t.Fatalf("Diff details not in path order: %v", result.DiffDetails)
+			}
+		}
+	}
+
+	opts.Order = orderArchive
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Different, entries) {
+		t.Errorf("Expected archive order %v, got %v", entries, result.Different)
+	}
+}
+
+func TestReportTimestamp(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if got := reportTimestamp(); got != "2023-11-14T22:13:20Z" {
+		t.Errorf("Expected time from SOURCE_DATE_EPOCH, got %s", got)
+	}
+
+	opts.NoTimestamp = true
+	if got := reportTimestamp(); got != "" {
+		t.Errorf("Expected no timestamp, got %s", got)
+	}
+}
+
+func TestReportsAreReproducible(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+	opts.NoTimestamp = true
+
+	files1 := map[string]string{"a.txt": "1", "b.txt": "2", "c.txt": "3", "d.txt": "4", "gone.txt": "x"}
+	files2 := map[string]string{"a.txt": "1", "b.txt": "two", "c.txt": "three", "d.txt": "4", "new.txt": "y"}
+	zip1, err := createTestZip(files1)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	defer os.Remove(zip1)
+	zip2, err := createTestZip(files2)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	for _, format := range reportFormatNames() {
+		var reports [][]byte
+		for i := 0; i < 3; i++ {
+			result, err := compareZipFiles(zip1, zip2)
+			if err != nil {
+				t.Fatalf("compareZipFiles failed: %v", err)
+			}
+			reportPath := filepath.Join(t.TempDir(), "report."+format)
+			if err := reportFormats[format](result, "a.zip", "b.zip", reportPath); err != nil {
+				t.Fatalf("%s report failed: %v", format, err)
+			}
+			report, err := os.ReadFile(reportPath)
+			if err != nil {
+				t.Fatalf("Failed to read report: %v", err)
+			}
+			reports = append(reports, report)
+		}
+		if !bytes.Equal(reports[0], reports[1]) || !bytes.Equal(reports[0], reports[2]) {
+			t.Errorf("%s reports differ between runs", format)
+		}
+		if bytes.Contains(reports[0], []byte("enerated")) {
+			t.Errorf("%s report contains a timestamp despite --no-timestamp", format)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"path"
+	"sort"
+)
+
+// RenameInfo describes a file that was renamed or moved between the archives
+type RenameInfo struct {
+	From       string  `xml:"from,attr" json:"from"`
+	To         string  `xml:"to,attr" json:"to"`
+	Similarity float64 `xml:"similarity,attr" json:"similarity"`    // 1 for identical content
+	Diff       string  `xml:"diff,omitempty" json:"diff,omitempty"` // Content diff for near matches
+}
+
+// lineSimilarity returns the share of lines two texts have in common,
+// from 0 (nothing in common) to 1 (identical)
+func lineSimilarity(content1, content2 string) float64 {
+	lines1 := splitLines(content1)
+	lines2 := splitLines(content2)
+	if len(lines1)+len(lines2) == 0 {
+		return 1
+	}
+
+	equal := 0
+	for _, e := range myersDiff(comparisonKeys(lines1), comparisonKeys(lines2)) {
+		if e.Op == editEqual {
+			equal++
+		}
+	}
+
+	return float64(2*equal) / float64(len(lines1)+len(lines2))
+}
+
+// detectRenames pairs files that exist only in the first archive with files
+// that exist only in the second one. Files with identical content are always
+// paired, text files with a line similarity of at least opts.RenameThreshold
+// are paired if the threshold is above 0. Paired files are moved from
+// OnlyInFirst and OnlyInSecond to Renamed.
+func detectRenames(result *ComparisonResult, files1, files2 map[string]FileInfo) {
+	if opts.NoRenames || len(result.OnlyInFirst) == 0 || len(result.OnlyInSecond) == 0 {
+		return
+	}
+
+	// Sorted copies keep the pairing independent of map order
+	onlyInFirst := append([]string(nil), result.OnlyInFirst...)
+	onlyInSecond := append([]string(nil), result.OnlyInSecond...)
+	sort.Strings(onlyInFirst)
+	sort.Strings(onlyInSecond)
+
+	claimed := make(map[string]bool)
+	paired := make(map[string]bool)
+
+	// Exact matches, a candidate with the same file name (a move) is preferred
+	byHash := make(map[string][]string)
+	for _, name := range onlyInSecond {
+		byHash[files2[name].Hash] = append(byHash[files2[name].Hash], name)
+	}
+	for _, from := range onlyInFirst {
+		var to string
+		for _, candidate := range byHash[files1[from].Hash] {
+			if claimed[candidate] || files2[candidate].Size != files1[from].Size {
+				continue
+			}
+			if to == "" || path.Base(candidate) == path.Base(from) && path.Base(to) != path.Base(from) {
+				to = candidate
+			}
+		}
+		if to != "" {
+			claimed[to] = true
+			paired[from] = true
+			result.Renamed = append(result.Renamed, RenameInfo{From: from, To: to, Similarity: 1})
+		}
+	}
+
+	// Near matches, each file takes the most similar remaining candidate
+	if opts.RenameThreshold > 0 {
+		for _, from := range onlyInFirst {
+			file1 := files1[from]
+			if paired[from] || file1.IsBinary || !withinDiffSize(file1.Size) {
+				continue
+			}
+
+			best, bestSimilarity := "", 0.0
+			for _, candidate := range onlyInSecond {
+				file2 := files2[candidate]
+				if claimed[candidate] || file2.IsBinary || !withinDiffSize(file2.Size) ||
+					!similarSize(file1.Size, file2.Size, opts.RenameThreshold) {
+					continue
+				}
+				similarity := lineSimilarity(file1.Content, file2.Content)
+				if similarity >= opts.RenameThreshold && similarity > bestSimilarity {
+					best, bestSimilarity = candidate, similarity
+				}
+			}
+
+			if best != "" {
+				claimed[best] = true
+				paired[from] = true
+				result.Renamed = append(result.Renamed, RenameInfo{
+					From:       from,
+					To:         best,
+					Similarity: bestSimilarity,
+					Diff:       generateDiffBetween(file1.Content, files2[best].Content, from, best),
+				})
+			}
+		}
+	}
+
+	result.OnlyInFirst = removeNames(result.OnlyInFirst, paired)
+	result.OnlyInSecond = removeNames(result.OnlyInSecond, claimed)
+}
+
+// similarSize reports whether two sizes are close enough to reach the
+// similarity threshold at all, which saves diffing obviously different files
+func similarSize(size1, size2 int64, threshold float64) bool {
+	if size1 > size2 {
+		size1, size2 = size2, size1
+	}
+	if size2 == 0 {
+		return true
+	}
+	return float64(2*size1)/float64(size1+size2) >= threshold
+}
+
+// removeNames returns names without the entries marked in remove
+func removeNames(names []string, remove map[string]bool) []string {
+	kept := []string{}
+	for _, name := range names {
+		if !remove[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// compareTestZips creates two ZIP files from the given contents and compares them
+func compareTestZips(t *testing.T, files1, files2 map[string]string) *ComparisonResult {
+	t.Helper()
+
+	zip1, err := createTestZip(files1)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	defer os.Remove(zip1)
+
+	zip2, err := createTestZip(files2)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+	return result
+}
+
+func TestDetectRenamesExact(t *testing.T) {
+	files1 := map[string]string{
+		"old/config.xml": "<config/>",
+		"readme.txt":     "read me",
+		"removed.txt":    "removed",
+	}
+
+	files2 := map[string]string{
+		"new/config.xml": "<config/>",
+		"README.md":      "read me",
+		"added.txt":      "added",
+	}
+
+	result := compareTestZips(t, files1, files2)
+
+	if len(result.Renamed) != 2 {
+		t.Fatalf("Expected 2 renamed files, got %+v", result.Renamed)
+	}
+
+	renames := make(map[string]RenameInfo)
+	for _, rename := range result.Renamed {
+		renames[rename.From] = rename
+	}
+	if rename := renames["old/config.xml"]; rename.To != "new/config.xml" || rename.Similarity != 1 {
+		t.Errorf("Expected old/config.xml to be moved to new/config.xml, got %+v", rename)
+	}
+	if rename := renames["readme.txt"]; rename.To != "README.md" {
+		t.Errorf("Expected readme.txt to be renamed to README.md, got %+v", rename)
+	}
+
+	if len(result.OnlyInFirst) != 1 || result.OnlyInFirst[0] != "removed.txt" {
+		t.Errorf("Expected only removed.txt only in first ZIP, got %v", result.OnlyInFirst)
+	}
+	if len(result.OnlyInSecond) != 1 || result.OnlyInSecond[0] != "added.txt" {
+		t.Errorf("Expected only added.txt only in second ZIP, got %v", result.OnlyInSecond)
+	}
+	if !hasDifferences(result) {
+		t.Error("Renamed files should count as differences")
+	}
+}
+
+func TestDetectRenamesNearMatch(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	files1 := map[string]string{"src/app.js": "line 1\nline 2\nline 3\nline 4\nline 5\n"}
+	files2 := map[string]string{"lib/app.js": "line 1\nline 2\nline three\nline 4\nline 5\n"}
+
+	// Without threshold only identical content is paired
+	result := compareTestZips(t, files1, files2)
+	if len(result.Renamed) != 0 {
+		t.Errorf("Expected no renames without threshold, got %+v", result.Renamed)
+	}
+
+	opts.RenameThreshold = 0.7
+	result = compareTestZips(t, files1, files2)
+	if len(result.Renamed) != 1 {
+		t.Fatalf("Expected 1 near match rename, got %+v", result.Renamed)
+	}
+
+	rename := result.Renamed[0]
+	if rename.Similarity != 0.8 {
+		t.Errorf("Expected similarity 0.8, got %g", rename.Similarity)
+	}
+	if !strings.Contains(rename.Diff, "--- a/src/app.js\n+++ b/lib/app.js\n") || !strings.Contains(rename.Diff, "+line three\n") {
+		t.Errorf("Expected diff between both names, got:\n%s", rename.Diff)
+	}
+
+	opts.RenameThreshold = 0.9
+	result = compareTestZips(t, files1, files2)
+	if len(result.Renamed) != 0 {
+		t.Errorf("Expected no renames below threshold, got %+v", result.Renamed)
+	}
+}
+
+func TestDetectRenamesDisabled(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.NoRenames = true
+	result := compareTestZips(t, map[string]string{"a.txt": "same"}, map[string]string{"b.txt": "same"})
+
+	if len(result.Renamed) != 0 || len(result.OnlyInFirst) != 1 || len(result.OnlyInSecond) != 1 {
+		t.Errorf("Expected no rename detection, got %+v", result)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// DirectorySummary counts the ZIP pairs of a directory comparison by status
+type DirectorySummary struct {
+	Pairs      int `xml:"pairs" json:"pairs"`
+	Identical  int `xml:"identical" json:"identical"`
+	Different  int `xml:"different" json:"different"`
+	Errors     int `xml:"errors" json:"errors"`
+	OnlyInDir1 int `xml:"onlyInDir1" json:"onlyInDir1"`
+	OnlyInDir2 int `xml:"onlyInDir2" json:"onlyInDir2"`
+}
+
+// XMLDirectoryReport is the aggregated XML report of a directory comparison
+type XMLDirectoryReport struct {
+	XMLName    xml.Name         `xml:"directoryComparison"`
+	Generated  string           `xml:"generated,attr,omitempty"`
+	Dir1       string           `xml:"dir1,attr"`
+	Dir2       string           `xml:"dir2,attr"`
+	Pairs      []PairResult     `xml:"pairs>pair"`
+	OnlyInDir1 []string         `xml:"onlyInDir1>zip"`
+	OnlyInDir2 []string         `xml:"onlyInDir2>zip"`
+	Summary    DirectorySummary `xml:"summary"`
+}
+
+// JSONDirectoryReport is the aggregated JSON report of a directory comparison
+type JSONDirectoryReport struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Generated     string           `json:"generated,omitempty"`
+	Dir1          string           `json:"dir1"`
+	Dir2          string           `json:"dir2"`
+	Pairs         []PairResult     `json:"pairs"`
+	OnlyInDir1    []string         `json:"onlyInDir1"`
+	OnlyInDir2    []string         `json:"onlyInDir2"`
+	Summary       DirectorySummary `json:"summary"`
+}
+
+// directoryReports maps each --format value to the aggregated report
+// written next to the pair reports in directory mode
+var directoryReports = map[string]struct {
+	fileName string
+	write    func(result *DirectoryResult, outputPath string) error
+}{
+	"xml":  {"summary.xml", generateXMLDirectoryReport},
+	"json": {"summary.json", generateJSONDirectoryReport},
+	"html": {"index.html", generateHTMLIndex},
+}
+
+// buildDirectorySummary counts the pairs in each status and the unmatched ZIP files
+func buildDirectorySummary(result *DirectoryResult) DirectorySummary {
+	summary := DirectorySummary{
+		Pairs:      len(result.Pairs),
+		OnlyInDir1: len(result.Unmatched.OnlyInDir1),
+		OnlyInDir2: len(result.Unmatched.OnlyInDir2),
+	}
+	for _, pair := range result.Pairs {
+		switch pair.Status {
+		case pairIdentical:
+			summary.Identical++
+		case pairDifferent:
+			summary.Different++
+		case pairError:
+			summary.Errors++
+		}
+	}
+	return summary
+}
+
+// generateXMLDirectoryReport creates the aggregated XML report of a directory comparison
+func generateXMLDirectoryReport(result *DirectoryResult, outputPath string) error {
+	report := XMLDirectoryReport{
+		Generated:  reportTimestamp(),
+		Dir1:       result.Dir1,
+		Dir2:       result.Dir2,
+		Pairs:      result.Pairs,
+		OnlyInDir1: result.Unmatched.OnlyInDir1,
+		OnlyInDir2: result.Unmatched.OnlyInDir2,
+		Summary:    buildDirectorySummary(result),
+	}
+
+	xmlData, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal XML: %w", err)
+	}
+
+	err = os.WriteFile(outputPath, []byte(xml.Header+string(xmlData)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write XML file: %w", err)
+	}
+
+	return nil
+}
+
+// generateJSONDirectoryReport creates the aggregated JSON report of a directory comparison
+func generateJSONDirectoryReport(result *DirectoryResult, outputPath string) error {
+	pairs := result.Pairs
+	if pairs == nil {
+		pairs = []PairResult{}
+	}
+
+	report := JSONDirectoryReport{
+		SchemaVersion: jsonSchemaVersion,
+		Generated:     reportTimestamp(),
+		Dir1:          result.Dir1,
+		Dir2:          result.Dir2,
+		Pairs:         pairs,
+		OnlyInDir1:    nonNilStrings(result.Unmatched.OnlyInDir1),
+		OnlyInDir2:    nonNilStrings(result.Unmatched.OnlyInDir2),
+		Summary:       buildDirectorySummary(result),
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	err = os.WriteFile(outputPath, append(jsonData, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupDirectoryPairs creates two directories with an identical pair "same",
+// a different pair "changed", a pair "broken" that cannot be read and
+// a ZIP "removed" without partner in the second directory
+func setupDirectoryPairs(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+
+	createTestZipIn(t, dir1, "same_v1.zip", map[string]string{"file.txt": "content"})
+	createTestZipIn(t, dir2, "same_v2.zip", map[string]string{"file.txt": "content"})
+	createTestZipIn(t, dir1, "changed_v1.zip", map[string]string{"file.txt": "old"})
+	createTestZipIn(t, dir2, "changed_v2.zip", map[string]string{"file.txt": "new", "added.txt": "added"})
+	createTestZipIn(t, dir1, "broken_v1.zip", map[string]string{"file.txt": "content"})
+	if err := os.WriteFile(filepath.Join(dir2, "broken_v2.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write broken ZIP: %v", err)
+	}
+	createTestZipIn(t, dir1, "removed_v1.zip", map[string]string{"file.txt": "content"})
+
+	return dir1, dir2
+}
+
+func TestJSONDirectoryReport(t *testing.T) {
+	dir1, dir2 := setupDirectoryPairs(t)
+	outputDir := t.TempDir()
+
+	if code := runQuiet(t, "--format", "json", dir1, dir2, outputDir); code != exitError {
+		t.Errorf("Expected exit code %d for a broken pair, got %d", exitError, code)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "summary.json"))
+	if err != nil {
+		t.Fatalf("Failed to read summary.json: %v", err)
+	}
+
+	var report JSONDirectoryReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse summary.json: %v", err)
+	}
+
+	expected := DirectorySummary{Pairs: 3, Identical: 1, Different: 1, Errors: 1, OnlyInDir1: 1}
+	if report.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, report.Summary)
+	}
+
+	if len(report.OnlyInDir1) != 1 || filepath.Base(report.OnlyInDir1[0]) != "removed_v1.zip" {
+		t.Errorf("Expected removed_v1.zip only in dir1, got %v", report.OnlyInDir1)
+	}
+	if report.OnlyInDir2 == nil {
+		t.Error("Expected an empty list for onlyInDir2")
+	}
+
+	pairs := make(map[string]PairResult)
+	for _, pair := range report.Pairs {
+		pairs[pair.BaseName] = pair
+	}
+
+	if pair := pairs["changed"]; pair.Status != pairDifferent || pair.Report != "changed_comparison.json" ||
+		pair.Summary.Different != 1 || pair.Summary.OnlyInSecond != 1 {
+		t.Errorf("Unexpected result for changed pair: %+v", pair)
+	}
+	if pair := pairs["broken"]; pair.Status != pairError || pair.Error == "" || pair.Report != "" {
+		t.Errorf("Unexpected result for broken pair: %+v", pair)
+	}
+	if pair := pairs["same"]; pair.Status != pairIdentical {
+		t.Errorf("Unexpected result for same pair: %+v", pair)
+	}
+}
+
+func TestXMLDirectoryReport(t *testing.T) {
+	dir1, dir2 := setupDirectoryPairs(t)
+	outputDir := t.TempDir()
+
+	runQuiet(t, dir1, dir2, outputDir)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "summary.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read summary.xml: %v", err)
+	}
+	xmlStr := string(data)
+
+	for _, expected := range []string{
+		"<directoryComparison",
+		`<pair name="changed" status="different">`,
+		"<report>changed_comparison.xml</report>",
+		`<pair name="broken" status="error">`,
+		"<errors>1</errors>",
+		"removed_v1.zip</zip>",
+		"<onlyInDir1>1</onlyInDir1>",
+	} {
+		if !strings.Contains(xmlStr, expected) {
+			t.Errorf("summary.xml should contain %q, got:\n%s", expected, xmlStr)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sideBySideRow is one row of a side-by-side diff table.
+// Line numbers are 0 where a side has no line.
+type sideBySideRow struct {
+	Hunk       string // Hunk header, the row only shows this text when set
+	LeftNo     int
+	Left       string
+	LeftClass  string
+	RightNo    int
+	Right      string
+	RightClass string
+
+	LeftSpans  []Span // Changed parts of a deleted line paired with an inserted line
+	RightSpans []Span // Changed parts of the inserted line
+}
+
+// htmlDiff is a different file prepared for the HTML report
+type htmlDiff struct {
+	FileName    string
+	IsBinary    bool
+	DiffSkipped bool
+	Rows        []sideBySideRow
+}
+
+// htmlRename is a renamed or moved file prepared for the HTML report
+type htmlRename struct {
+	From       string
+	To         string
+	Similarity string // Percentage, empty for identical content
+	Rows       []sideBySideRow
+}
+
+// htmlReport is the data passed to htmlReportTemplate
+type htmlReport struct {
+	Generated    string
+	Zip1         string
+	Zip2         string
+	Modes        []string
+	Summary      Summary
+	Identical    []string
+	Different    []htmlDiff
+	OnlyInFirst  []string
+	OnlyInSecond []string
+	Renamed      []htmlRename
+	Warnings     []string
+	Normalized   []string
+
+	MetadataDiffers []MetadataDiff
+	ArchiveMetadata []MetadataField
+}
+
+// parseHunkStart reads the start line of one side of a hunk header like "-3,4"
+func parseHunkStart(spec string) int {
+	start, _, _ := strings.Cut(spec[1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// sideBySideRows converts a unified diff into rows for a side-by-side view.
+// Runs of deleted lines are paired with the following inserted lines.
+func sideBySideRows(diff string) []sideBySideRow {
+	var rows []sideBySideRow
+	var deleted, inserted []string
+	leftNo, rightNo := 0, 0
+
+	flush := func() {
+		for i := 0; i < len(deleted) || i < len(inserted); i++ {
+			row := sideBySideRow{LeftClass: "empty", RightClass: "empty"}
+			if i < len(deleted) {
+				leftNo++
+				row.LeftNo, row.Left, row.LeftClass = leftNo, deleted[i], "del"
+			}
+			if i < len(inserted) {
+				rightNo++
+				row.RightNo, row.Right, row.RightClass = rightNo, inserted[i], "ins"
+			}
+			if i < len(deleted) && i < len(inserted) {
+				row.LeftSpans, row.RightSpans = intralineSpans(deleted[i], inserted[i])
+			}
+			rows = append(rows, row)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for i, line := range strings.Split(diff, "\n") {
+		switch {
+		case i < 2:
+			// File headers are shown in the section title. Later lines starting
+			// with "--- " or "+++ " are deleted or inserted "-- " and "++ " lines.
+		case strings.HasPrefix(line, "@@ "):
+			flush()
+			fields := strings.Fields(line)
+			if len(fields) >= 3 {
+				// Counters hold the number of the line before the next one
+				leftNo = parseHunkStart(fields[1]) - 1
+				rightNo = parseHunkStart(fields[2]) - 1
+			}
+			rows = append(rows, sideBySideRow{Hunk: line})
+		case strings.HasPrefix(line, "-"):
+			deleted = append(deleted, line[1:])
+		case strings.HasPrefix(line, "+"):
+			inserted = append(inserted, line[1:])
+		case strings.HasPrefix(line, " "):
+			flush()
+			leftNo++
+			rightNo++
+			rows = append(rows, sideBySideRow{
+				LeftNo: leftNo, Left: line[1:], LeftClass: "ctx",
+				RightNo: rightNo, Right: line[1:], RightClass: "ctx",
+			})
+		}
+	}
+	flush()
+
+	return rows
+}
+
+// buildHTMLReport prepares the template data for a comparison result
+func buildHTMLReport(result *ComparisonResult, zip1Path, zip2Path string) htmlReport {
+	report := htmlReport{
+		Generated:    reportTimestamp(),
+		Zip1:         zip1Path,
+		Zip2:         zip2Path,
+		Modes:        result.Modes,
+		Summary:      buildSummary(result),
+		Identical:    result.Identical,
+		OnlyInFirst:  result.OnlyInFirst,
+		OnlyInSecond: result.OnlyInSecond,
+		Warnings:     result.Warnings,
+		Normalized:   result.Normalized,
+
+		MetadataDiffers: result.MetadataDiffers,
+		ArchiveMetadata: result.ArchiveMetadata,
+	}
+
+	for _, rename := range result.Renamed {
+		renamed := htmlRename{From: rename.From, To: rename.To}
+		if rename.Similarity < 1 {
+			renamed.Similarity = fmt.Sprintf("%.0f%%", rename.Similarity*100)
+			renamed.Rows = sideBySideRows(rename.Diff)
+		}
+		report.Renamed = append(report.Renamed, renamed)
+	}
+
+	for _, detail := range result.DiffDetails {
+		diff := htmlDiff{FileName: detail.FileName, IsBinary: detail.IsBinary, DiffSkipped: detail.DiffSkipped}
+		if !detail.IsBinary {
+			diff.Rows = sideBySideRows(detail.Diff)
+		}
+		report.Different = append(report.Different, diff)
+	}
+
+	return report
+}
+
+// writeHTMLTemplate renders tmpl with data into outputPath
+func writeHTMLTemplate(tmpl *template.Template, data any, outputPath string) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write HTML file: %w", err)
+	}
+
+	return nil
+}
+
+// generateHTMLReport creates a self-contained HTML report with side-by-side diffs
+func generateHTMLReport(result *ComparisonResult, zip1Path, zip2Path, outputPath string) error {
+	return writeHTMLTemplate(htmlReportTemplate, buildHTMLReport(result, zip1Path, zip2Path), outputPath)
+}
+
+// htmlIndex is the data passed to htmlIndexTemplate
+type htmlIndex struct {
+	Generated  string
+	Dir1       string
+	Dir2       string
+	Pairs      []PairResult
+	OnlyInDir1 []string
+	OnlyInDir2 []string
+}
+
+// generateHTMLIndex creates index.html linking the reports of all ZIP pairs
+func generateHTMLIndex(result *DirectoryResult, outputPath string) error {
+	index := htmlIndex{
+		Generated: reportTimestamp(),
+		Dir1:      result.Dir1,
+		Dir2:      result.Dir2,
+		Pairs:     result.Pairs,
+	}
+	for _, zipPath := range result.Unmatched.OnlyInDir1 {
+		index.OnlyInDir1 = append(index.OnlyInDir1, filepath.Base(zipPath))
+	}
+	for _, zipPath := range result.Unmatched.OnlyInDir2 {
+		index.OnlyInDir2 = append(index.OnlyInDir2, filepath.Base(zipPath))
+	}
+	return writeHTMLTemplate(htmlIndexTemplate, index, outputPath)
+}
+
+// htmlFuncs gives the templates access to the message catalog
+var htmlFuncs = template.FuncMap{
+	"t":     tr,
+	"lang":  activeLanguage,
+	"parts": splitSpans,
+}
+
+// htmlStyle is shared by the pair reports and the index page
+const htmlStyle = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 1.5em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+code, .diff td { font-family: Consolas, "Liberation Mono", Menlo, monospace; font-size: 12px; }
+.meta { color: #59636e; }
+table.summary { border-collapse: collapse; }
+table.summary th, table.summary td { border: 1px solid #d0d7de; padding: 4px 12px; text-align: left; }
+table.summary td.count { text-align: right; }
+details { margin: .5em 0; border: 1px solid #d0d7de; border-radius: 6px; }
+details > summary { cursor: pointer; padding: 6px 10px; background: #f6f8fa; font-family: Consolas, Menlo, monospace; }
+details > ul { margin: .5em 0; }
+table.diff { width: 100%; border-collapse: collapse; table-layout: fixed; }
+table.diff col.num { width: 4em; }
+table.diff td { padding: 0 6px; white-space: pre-wrap; word-break: break-all; vertical-align: top; }
+table.diff td.num { color: #59636e; text-align: right; user-select: none; }
+table.diff tr.hunk td { background: #ddf4ff; color: #59636e; }
+table.diff td.del { background: #ffebe9; }
+table.diff td.ins { background: #e6ffec; }
+table.diff td.empty { background: #f6f8fa; }
+table.diff td.del span.chg { background: #ffcecb; }
+table.diff td.ins span.chg { background: #abf2bc; }
+.binary { padding: 6px 10px; color: #59636e; }
+.status-identical { color: #1a7f37; }
+.status-different { color: #9a6700; }
+.status-error { color: #d1242f; }
+`
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "html.title"}}</title>
+<style>` + htmlStyle + `</style>
+</head>
+<body>
+<h1>{{t "html.title"}}</h1>
+<p class="meta">{{if .Generated}}{{t "html.generated"}}: {{.Generated}}<br>
+{{end}}
+ZIP 1: <code>{{.Zip1}}</code><br>
+ZIP 2: <code>{{.Zip2}}</code>{{if .Modes}}<br>
+{{t "html.modes"}}: {{range $i, $mode := .Modes}}{{if $i}}, {{end}}<code>{{$mode}}</code>{{end}}{{end}}</p>
+
+<h2>{{t "summary"}}</h2>
+<table class="summary">
+<tr><th>{{t "totalFiles"}}</th><td class="count">{{.Summary.Total}}</td></tr>
+<tr><th>{{t "identical"}}</th><td class="count">{{.Summary.Identical}}</td></tr>
+<tr><th>{{t "different"}}</th><td class="count">{{.Summary.Different}}</td></tr>
+<tr><th>{{t "onlyInZip1"}}</th><td class="count">{{.Summary.OnlyInFirst}}</td></tr>
+<tr><th>{{t "onlyInZip2"}}</th><td class="count">{{.Summary.OnlyInSecond}}</td></tr>
+<tr><th>{{t "renamed"}}</th><td class="count">{{.Summary.Renamed}}</td></tr>
+{{if .MetadataDiffers}}<tr><th>{{t "metadataDiffers"}}</th><td class="count">{{.Summary.MetadataDiffers}}</td></tr>
+{{end}}{{if .Summary.Ignored}}<tr><th>{{t "ignored"}}</th><td class="count">{{.Summary.Ignored}}</td></tr>
+{{end}}</table>
+{{if .Warnings}}
+<h2>{{t "warnings"}}</h2>
+<ul>{{range .Warnings}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+{{if or .MetadataDiffers .ArchiveMetadata}}
+<h2>{{t "metadataDiffers"}} ({{len .MetadataDiffers}})</h2>
+<table class="summary">
+<tr><th>{{t "html.file"}}</th><th>{{t "html.field"}}</th><th>ZIP 1</th><th>ZIP 2</th></tr>
+{{range .ArchiveMetadata}}<tr><td><em>{{t "html.archive"}}</em></td><td>{{.Name}}</td><td><code>{{.First}}</code></td><td><code>{{.Second}}</code></td></tr>
+{{end}}{{range $file := .MetadataDiffers}}{{range .Fields}}<tr><td>{{$file.FileName}}</td><td>{{.Name}}</td><td><code>{{.First}}</code></td><td><code>{{.Second}}</code></td></tr>
+{{end}}{{end}}</table>
+{{end}}
+{{if .Normalized}}
+<h2>{{t "html.normalizedFiles"}} ({{len .Normalized}})</h2>
+<p>{{t "html.normalizedNote"}}</p>
+<ul>{{range .Normalized}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+{{if .Different}}
+<h2>{{t "differentFiles"}} ({{len .Different}})</h2>
+{{range .Different}}<details open>
+<summary>{{.FileName}}{{if .IsBinary}} ({{t "html.binary"}}){{end}}</summary>
+{{if .IsBinary}}<div class="binary">{{t "html.binaryDiffer"}}</div>
+{{else if .DiffSkipped}}<div class="binary">{{t "html.diffSkipped"}}</div>
+{{else}}{{template "diffTable" .Rows}}
+{{end}}</details>
+{{end}}{{end}}
+{{if .Renamed}}
+<h2>{{t "html.renamedFiles"}} ({{len .Renamed}})</h2>
+{{range .Renamed}}{{if .Rows}}<details open>
+<summary>{{.From}} → {{.To}} ({{t "html.similar" .Similarity}})</summary>
+{{template "diffTable" .Rows}}
+</details>
+{{else}}<details><summary>{{.From}} → {{.To}}</summary></details>
+{{end}}{{end}}{{end}}
+{{if .OnlyInFirst}}
+<h2>{{t "onlyInZip1"}} ({{len .OnlyInFirst}})</h2>
+<ul>{{range .OnlyInFirst}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{end}}
+{{if .OnlyInSecond}}
+<h2>{{t "onlyInZip2"}} ({{len .OnlyInSecond}})</h2>
+<ul>{{range .OnlyInSecond}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{end}}
+{{if .Identical}}
+<h2>{{t "identicalFiles"}} ({{len .Identical}})</h2>
+<details>
+<summary>{{t "html.showIdentical"}}</summary>
+<ul>{{range .Identical}}<li><code>{{.}}</code></li>{{end}}</ul>
+</details>
+{{end}}
+</body>
+</html>
+{{define "diffTable"}}<table class="diff">
+<colgroup><col class="num"><col><col class="num"><col></colgroup>
+{{range .}}{{if .Hunk}}<tr class="hunk"><td colspan="4">{{.Hunk}}</td></tr>
+{{else}}<tr><td class="num">{{if .LeftNo}}{{.LeftNo}}{{end}}</td><td class="{{.LeftClass}}">{{template "line" (parts .Left .LeftSpans)}}</td><td class="num">{{if .RightNo}}{{.RightNo}}{{end}}</td><td class="{{.RightClass}}">{{template "line" (parts .Right .RightSpans)}}</td></tr>
+{{end}}{{end}}</table>{{end}}
+{{define "line"}}{{range .}}{{if .Changed}}<span class="chg">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}{{end}}`))
+
+var htmlIndexTemplate = template.Must(template.New("index").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "html.indexTitle"}}</title>
+<style>` + htmlStyle + `</style>
+</head>
+<body>
+<h1>{{t "html.indexTitle"}}</h1>
+<p class="meta">{{if .Generated}}{{t "html.generated"}}: {{.Generated}}<br>
+{{end}}
+{{t "directory1"}}: <code>{{.Dir1}}</code><br>
+{{t "directory2"}}: <code>{{.Dir2}}</code></p>
+
+<table class="summary">
+<tr><th>{{t "html.pair"}}</th><th>{{t "html.status"}}</th><th>{{t "html.total"}}</th><th>{{t "identical"}}</th><th>{{t "different"}}</th><th>{{t "html.onlyIn1"}}</th><th>{{t "html.onlyIn2"}}</th><th>{{t "html.renamedShort"}}</th></tr>
+{{range .Pairs}}<tr>
+<td>{{if .Report}}<a href="{{.Report}}">{{.BaseName}}</a>{{else}}{{.BaseName}}{{end}}</td>
+<td class="status-{{.Status}}">{{t (printf "html.status.%s" .Status)}}{{if .Error}}: {{.Error}}{{end}}</td>
+<td class="count">{{.Summary.Total}}</td>
+<td class="count">{{.Summary.Identical}}</td>
+<td class="count">{{.Summary.Different}}</td>
+<td class="count">{{.Summary.OnlyInFirst}}</td>
+<td class="count">{{.Summary.OnlyInSecond}}</td>
+<td class="count">{{.Summary.Renamed}}</td>
+</tr>
+{{end}}</table>
+{{if .OnlyInDir1}}
+<h2>{{t "onlyInDir1"}} ({{len .OnlyInDir1}})</h2>
+<ul>{{range .OnlyInDir1}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{end}}
+{{if .OnlyInDir2}}
+<h2>{{t "onlyInDir2"}} ({{len .OnlyInDir2}})</h2>
+<ul>{{range .OnlyInDir2}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSideBySideRows(t *testing.T) {
+	diff := "--- a/f.txt\n+++ b/f.txt\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n+new\n c\n"
+
+	rows := sideBySideRows(diff)
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %d: %+v", len(rows), rows)
+	}
+
+	if rows[0].Hunk != "@@ -1,3 +1,4 @@" {
+		t.Errorf("Expected hunk header row, got %+v", rows[0])
+	}
+
+	// The deleted line is paired with the first inserted line
+	changed := rows[2]
+	if changed.Left != "b" || changed.LeftNo != 2 || changed.Right != "B" || changed.RightNo != 2 {
+		t.Errorf("Unexpected changed row: %+v", changed)
+	}
+
+	inserted := rows[3]
+	if inserted.LeftNo != 0 || inserted.LeftClass != "empty" || inserted.Right != "new" || inserted.RightNo != 3 {
+		t.Errorf("Unexpected inserted row: %+v", inserted)
+	}
+
+	last := rows[4]
+	if last.LeftNo != 3 || last.RightNo != 4 || last.LeftClass != "ctx" {
+		t.Errorf("Unexpected context row: %+v", last)
+	}
+}
+
+func TestSideBySideRowsDashedContent(t *testing.T) {
+	// SQL comments: deleted "-- old" and inserted "++ new" look like file headers
+	diff := generateDiff("SELECT 1;\n-- old\n", "SELECT 1;\n++ new\n", "query.sql")
+
+	rows := sideBySideRows(diff)
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+
+	changed := rows[2]
+	if changed.Left != "-- old" || changed.LeftNo != 2 || changed.Right != "++ new" || changed.RightNo != 2 {
+		t.Errorf("Unexpected changed row: %+v", changed)
+	}
+}
+
+func TestHTMLReportGeneration(t *testing.T) {
+	result := &ComparisonResult{
+		Identical:   []string{"same.txt"},
+		Different:   []string{"page.html", "app.exe"},
+		OnlyInFirst: []string{"old.txt"},
+		DiffDetails: []DiffInfo{
+			{FileName: "page.html", Diff: generateDiff("<b>old</b>\n", "<b>new</b>\n", "page.html")},
+			{FileName: "app.exe", IsBinary: true},
+		},
+	}
+
+	htmlFile := filepath.Join(t.TempDir(), "report.html")
+	if err := generateHTMLReport(result, "a.zip", "b.zip", htmlFile); err != nil {
+		t.Fatalf("generateHTMLReport failed: %v", err)
+	}
+
+	content, err := os.ReadFile(htmlFile)
+	if err != nil {
+		t.Fatalf("Failed to read HTML file: %v", err)
+	}
+	html := string(content)
+
+	for _, expected := range []string{
+		"<style>",
+		"<details open>",
+		`<td class="del">&lt;b&gt;<span class="chg">old</span>&lt;/b&gt;</td>`,
+		`<td class="ins">&lt;b&gt;<span class="chg">new</span>&lt;/b&gt;</td>`,
+		"Binary files differ",
+		"<code>old.txt</code>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("HTML report should contain %q", expected)
+		}
+	}
+
+	// Self-contained: no external resources
+	if strings.Contains(html, "<link") || strings.Contains(html, "<script src") {
+		t.Error("HTML report should not reference external resources")
+	}
+}
+
+func TestHTMLIndexForDirectories(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "reports")
+
+	createTestZipIn(t, dir1, "package_v1.zip", map[string]string{"file.txt": "v1"})
+	createTestZipIn(t, dir2, "package_v2.zip", map[string]string{"file.txt": "v2"})
+
+	if code := runQuiet(t, "--format", "html", dir1, dir2, outputDir); code != exitDifferences {
+		t.Fatalf("Expected exit code %d, got %d", exitDifferences, code)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index.html: %v", err)
+	}
+
+	if !strings.Contains(string(index), `<a href="package_comparison.html">package</a>`) {
+		t.Errorf("index.html should link the pair report, got:\n%s", index)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "package_comparison.html")); err != nil {
+		t.Errorf("Expected pair report: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonSchemaVersion is increased whenever fields of JSONReport are renamed,
+// removed or change their meaning. Adding fields keeps the version.
+const jsonSchemaVersion = 1
+
+// JSONReport is the document written by --format json, see README for the schema
+type JSONReport struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Generated     string       `json:"generated,omitempty"`
+	Zip1          string       `json:"zip1"`
+	Zip2          string       `json:"zip2"`
+	Modes         []string     `json:"modes"`
+	Identical     []string     `json:"identical"`
+	Different     []DiffInfo   `json:"different"`
+	OnlyInFirst   []string     `json:"onlyInFirst"`
+	OnlyInSecond  []string     `json:"onlyInSecond"`
+	Renamed       []RenameInfo `json:"renamed"`
+	Warnings      []string     `json:"warnings"`
+	Normalized    []string     `json:"normalized"`
+
+	MetadataDiffers []MetadataDiff  `json:"metadataDiffers"`
+	ArchiveMetadata []MetadataField `json:"archiveMetadata"`
+
+	Summary Summary `json:"summary"`
+}
+
+// nonNilStrings returns an empty slice for nil, so JSON lists are never null
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// generateJSONReport creates a JSON report with detailed comparison results
+func generateJSONReport(result *ComparisonResult, zip1Path, zip2Path, outputPath string) error {
+	different := result.DiffDetails
+	if different == nil {
+		different = []DiffInfo{}
+	}
+	renamed := result.Renamed
+	if renamed == nil {
+		renamed = []RenameInfo{}
+	}
+	metadataDiffers := result.MetadataDiffers
+	if metadataDiffers == nil {
+		metadataDiffers = []MetadataDiff{}
+	}
+	archiveMetadata := result.ArchiveMetadata
+	if archiveMetadata == nil {
+		archiveMetadata = []MetadataField{}
+	}
+
+	report := JSONReport{
+		SchemaVersion: jsonSchemaVersion,
+		Generated:     reportTimestamp(),
+		Zip1:          zip1Path,
+		Zip2:          zip2Path,
+		Modes:         nonNilStrings(result.Modes),
+		Identical:     nonNilStrings(result.Identical),
+		Different:     different,
+		OnlyInFirst:   nonNilStrings(result.OnlyInFirst),
+		OnlyInSecond:  nonNilStrings(result.OnlyInSecond),
+		Renamed:       renamed,
+		Warnings:      nonNilStrings(result.Warnings),
+		Normalized:    nonNilStrings(result.Normalized),
+
+		MetadataDiffers: metadataDiffers,
+		ArchiveMetadata: archiveMetadata,
+
+		Summary: buildSummary(result),
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	err = os.WriteFile(outputPath, append(jsonData, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	return nil
+}
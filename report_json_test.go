@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONReportGeneration(t *testing.T) {
+	files1 := map[string]string{
+		"same.txt":      "identical content",
+		"different.txt": "content in zip1\nline 2\n",
+		"binary.exe":    "\x00\x01\x02\x03",
+	}
+
+	files2 := map[string]string{
+		"same.txt":      "identical content",
+		"different.txt": "content in zip2\nline 2\n",
+		"binary.exe":    "\x00\x01\x02\x04",
+		"new.txt":       "new",
+	}
+
+	zip1, err := createTestZip(files1)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 1: %v", err)
+	}
+	defer os.Remove(zip1)
+
+	zip2, err := createTestZip(files2)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP 2: %v", err)
+	}
+	defer os.Remove(zip2)
+
+	result, err := compareZipFiles(zip1, zip2)
+	if err != nil {
+		t.Fatalf("compareZipFiles failed: %v", err)
+	}
+
+	jsonFile := filepath.Join(t.TempDir(), "report.json")
+	if err := generateJSONReport(result, zip1, zip2, jsonFile); err != nil {
+		t.Fatalf("generateJSONReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON file: %v", err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse JSON report: %v", err)
+	}
+
+	if report.SchemaVersion != jsonSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", jsonSchemaVersion, report.SchemaVersion)
+	}
+	if report.Zip1 != zip1 || report.Zip2 != zip2 {
+		t.Errorf("Expected input paths %s and %s, got %s and %s", zip1, zip2, report.Zip1, report.Zip2)
+	}
+	if report.Summary.Total != 4 || report.Summary.Different != 2 || report.Summary.OnlyInSecond != 1 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+
+	for _, diff := range report.Different {
+		switch diff.FileName {
+		case "different.txt":
+			if diff.IsBinary || !strings.Contains(diff.Diff, "-content in zip1") {
+				t.Errorf("Expected text diff for different.txt, got %+v", diff)
+			}
+		case "binary.exe":
+			if !diff.IsBinary {
+				t.Error("binary.exe should be marked as binary")
+			}
+		}
+	}
+
+	// Empty categories are written as empty lists, never as null
+	if !strings.Contains(string(data), `"onlyInFirst": []`) || !strings.Contains(string(data), `"warnings": []`) {
+		t.Errorf("Expected empty lists for empty categories, got:\n%s", data)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// binaryDetector classifies a stream like isBinaryContent without keeping it
+type binaryDetector struct {
+	binary bool
+	carry  []byte // Incomplete UTF-8 sequence at the end of the last write
+}
+
+func (d *binaryDetector) Write(p []byte) (int, error) {
+	if d.binary {
+		return len(p), nil
+	}
+
+	data := p
+	if len(d.carry) > 0 {
+		data = append(d.carry, p...)
+		d.carry = nil
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		d.binary = true
+		return len(p), nil
+	}
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			if !utf8.FullRune(data[i:]) {
+				d.carry = append([]byte(nil), data[i:]...)
+				break
+			}
+			d.binary = true
+			break
+		}
+		i += size
+	}
+
+	return len(p), nil
+}
+
+// isBinary reports the result after the whole stream was written
+func (d *binaryDetector) isBinary() bool {
+	return d.binary || len(d.carry) > 0
+}
+
+// maxNormalizedLine bounds the bytes buffered for a single line. From a longer
+// line on, like in minified JavaScript or a single line XML dump, the rest of
+// the file is hashed as it is, so normalizing never holds a whole file in memory.
+const maxNormalizedLine = 1 << 20
+
+// lineNormalizer hashes text line by line with the BOM, line filters and
+// whitespace modes applied, as compared in place of the content
+type lineNormalizer struct {
+	hash     hash.Hash
+	detector *binaryDetector // Processing stops once the content is binary
+	patterns []*regexp.Regexp
+	stripBOM bool
+	keys     bool // Lines are hashed as comparison keys, separated by newlines
+
+	pending []byte // Incomplete last line
+	raw     bool   // A line exceeded maxNormalizedLine, the rest is hashed unchanged
+	seen    bool   // The first line was processed
+	lines   int    // Number of lines hashed
+	size    int64  // Number of bytes hashed
+	removed bool   // A line filter removed at least one line
+}
+
+// newLineNormalizer returns a normalizer for the file, or nil if its text is compared as it is
+func newLineNormalizer(detector *binaryDetector, names ...string) *lineNormalizer {
+	n := &lineNormalizer{
+		hash:     sha256.New(),
+		detector: detector,
+		patterns: lineFilterPatterns(names...),
+		stripBOM: opts.IgnoreBOM,
+		keys:     opts.ignoresLineDifferences(),
+	}
+	if len(n.patterns) == 0 && !n.stripBOM && !n.keys {
+		return nil
+	}
+	return n
+}
+
+func (n *lineNormalizer) Write(p []byte) (int, error) {
+	if n.detector.binary {
+		n.pending = nil
+		return len(p), nil
+	}
+
+	if n.raw {
+		n.hashRaw(p)
+		return len(p), nil
+	}
+
+	n.pending = append(n.pending, p...)
+	start := 0
+	for {
+		end := bytes.IndexByte(n.pending[start:], '\n')
+		if end < 0 || end+1 > maxNormalizedLine {
+			break
+		}
+		n.line(string(n.pending[start : start+end+1]))
+		start += end + 1
+	}
+	n.pending = append(n.pending[:0], n.pending[start:]...)
+
+	// A long line switches to raw hashing at its start, however it was split into writes
+	if len(n.pending) > maxNormalizedLine {
+		n.raw = true
+		n.hashRaw(n.pending)
+		n.pending = nil
+	}
+	return len(p), nil
+}
+
+// hashRaw hashes content without normalizing it
+func (n *lineNormalizer) hashRaw(p []byte) {
+	n.hash.Write(p)
+	n.size += int64(len(p))
+}
+
+// line hashes one line including its line break
+func (n *lineNormalizer) line(line string) {
+	first := !n.seen
+	n.seen = true
+	if n.stripBOM && first {
+		line = strings.TrimPrefix(line, utf8BOM)
+		if line == "" {
+			return
+		}
+	}
+
+	if matchesAnyLine(n.patterns, strings.TrimRight(line, "\r\n")) {
+		n.removed = true
+		return
+	}
+
+	if n.keys {
+		if n.lines > 0 {
+			n.hash.Write([]byte("\n"))
+			n.size++
+		}
+		line = comparisonKey(line)
+	}
+	n.hash.Write([]byte(line))
+	n.size += int64(len(line))
+	n.lines++
+}
+
+// close hashes the last line if it has no line break
+func (n *lineNormalizer) close() {
+	if len(n.pending) > 0 {
+		n.line(string(n.pending))
+		n.pending = nil
+	}
+}
+
+// hashEntry reads an entry once and hashes it without keeping the content.
+// Line filters and the whitespace modes are applied to text content before
+// hashing, Size is the size of the hashed content then.
+func hashEntry(name, key string, content io.Reader) (FileInfo, error) {
+	raw := sha256.New()
+	detector := &binaryDetector{}
+	writers := []io.Writer{raw, detector}
+	normalizer := newLineNormalizer(detector, name, key)
+	if normalizer != nil {
+		writers = append(writers, normalizer)
+	}
+
+	size, err := io.Copy(io.MultiWriter(writers...), content)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to read file %s: %w", name, err)
+	}
+
+	fileInfo := FileInfo{
+		Name:     name,
+		BaseName: key,
+		Size:     size,
+		Hash:     fmt.Sprintf("%x", raw.Sum(nil)),
+		IsBinary: detector.isBinary(),
+	}
+
+	if !fileInfo.IsBinary && normalizer != nil {
+		normalizer.close()
+		fileInfo.Size = normalizer.size
+		fileInfo.Hash = fmt.Sprintf("%x", normalizer.hash.Sum(nil))
+		fileInfo.Normalized = normalizer.removed
+	}
+
+	return fileInfo, nil
+}
+
+// readTextContent reads the text of an entry for diffs, with the BOM and
+// line filters applied like in hashEntry
+func readTextContent(name, key string, content io.Reader) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", name, err)
+	}
+
+	text := string(data)
+	if opts.IgnoreBOM {
+		text = strings.TrimPrefix(text, utf8BOM)
+	}
+	text, _ = normalizeContent(text, name, key)
+	return text, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBinaryDetectorMatchesIsBinaryContent(t *testing.T) {
+	inputs := [][]byte{
+		[]byte("plain text\n"),
+		[]byte("Grüße, 日本語 ✓\n"),
+		[]byte("null\x00byte"),
+		{0xff, 0xfe, 0x41},
+		[]byte("cut rune \xe2\x9c"),
+		{},
+	}
+
+	for _, input := range inputs {
+		// Write byte by byte to split multi-byte runes between writes
+		detector := &binaryDetector{}
+		for i := range input {
+			detector.Write(input[i : i+1])
+		}
+		if got, expected := detector.isBinary(), isBinaryContent(input); got != expected {
+			t.Errorf("binaryDetector(%q) = %v, isBinaryContent = %v", input, got, expected)
+		}
+	}
+}
+
+func TestHashEntryAppliesNormalization(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.IgnoreBOM = true
+	opts.IgnoreEOL = true
+	opts.IgnoreLines = []string{`^Built: `}
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	// One byte reads split lines and the BOM between writes
+	info1, err := hashEntry("a.txt", "a.txt", iotest.OneByteReader(strings.NewReader("\uFEFFBuilt: 1\r\nline\r\nlast")))
+	if err != nil {
+		t.Fatalf("hashEntry failed: %v", err)
+	}
+	info2, err := hashEntry("a.txt", "a.txt", strings.NewReader("line\nBuilt: 2\nlast\n"))
+	if err != nil {
+		t.Fatalf("hashEntry failed: %v", err)
+	}
+
+	if info1.Hash != info2.Hash || info1.Size != info2.Size {
+		t.Errorf("Expected equal hashes after normalization, got %+v and %+v", info1, info2)
+	}
+	if !info1.Normalized || !info2.Normalized {
+		t.Error("Expected both files to be marked as normalized")
+	}
+	if info1.Content != "" {
+		t.Errorf("hashEntry must not keep the content, got %q", info1.Content)
+	}
+}
+
+func TestMaxDiffSizeSkipsLargeDiffs(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.MaxDiffSize = 10
+	result := compareTestZips(t,
+		map[string]string{"small.txt": "old\n", "large.txt": "old content that is long\n"},
+		map[string]string{"small.txt": "new\n", "large.txt": "new content that is long\n"})
+
+	details := make(map[string]DiffInfo)
+	for _, detail := range result.DiffDetails {
+		details[detail.FileName] = detail
+	}
+
+	if details["small.txt"].Diff == "" || details["small.txt"].DiffSkipped {
+		t.Errorf("Expected a diff for small.txt, got %+v", details["small.txt"])
+	}
+	if details["large.txt"].Diff != "" || !details["large.txt"].DiffSkipped {
+		t.Errorf("Expected the diff of large.txt to be skipped, got %+v", details["large.txt"])
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "large.txt") {
+		t.Errorf("Expected a warning for large.txt, got %v", result.Warnings)
+	}
+}
+
+func TestDiffBatches(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts = defaultOptions()
+	opts.MaxDiffSize = 0
+	const mib = 1 << 20
+	files1 := map[string]FileInfo{
+		"a.txt": {Size: 10 * mib}, "b.txt": {Size: 10 * mib}, "c.txt": {Size: 40 * mib},
+		"d.bin": {Size: mib, IsBinary: true}, "e.txt": {Size: 100 * mib}, "f.txt": {Size: 1},
+	}
+	files2 := map[string]FileInfo{
+		"a.txt": {Size: 10 * mib}, "b.txt": {Size: 10 * mib}, "c.txt": {Size: 40 * mib},
+		"d.bin": {Size: mib, IsBinary: true}, "e.txt": {Size: 100 * mib}, "f.txt": {Size: 1},
+	}
+	result := &ComparisonResult{Different: []string{"a.txt", "b.txt", "c.txt", "d.bin", "e.txt", "f.txt"}}
+
+	// Pairs are batched up to diffBatchSize bytes, a larger pair is read on its own
+	expected := [][]string{{"a.txt", "b.txt"}, {"c.txt"}, {"e.txt"}, {"f.txt"}}
+	if batches := diffBatches(result, files1, files2); !reflect.DeepEqual(batches, expected) {
+		t.Errorf("diffBatches = %v, expected %v", batches, expected)
+	}
+}
+
+func TestLineNormalizerBoundsLongLines(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts = defaultOptions()
+	opts.IgnoreCase = true
+	if err := opts.compile(); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	// A file without line breaks, like minified JavaScript, is not buffered whole
+	content := "Header\n" + strings.Repeat("var a=1;", 2*maxNormalizedLine/8)
+	normalizer := newLineNormalizer(&binaryDetector{}, "app.min.js")
+	chunk := []byte(content)
+	for len(chunk) > 0 {
+		n := min(len(chunk), 32<<10)
+		normalizer.Write(chunk[:n])
+		chunk = chunk[n:]
+		if len(normalizer.pending) > maxNormalizedLine {
+			t.Fatalf("normalizer buffered %d bytes", len(normalizer.pending))
+		}
+	}
+
+	// The lines before the long one are still normalized, the hash does not depend on reads
+	info1, err := hashEntry("app.min.js", "app.min.js", iotest.HalfReader(strings.NewReader(content)))
+	if err != nil {
+		t.Fatalf("hashEntry failed: %v", err)
+	}
+	info2, err := hashEntry("app.min.js", "app.min.js", strings.NewReader(strings.Replace(content, "Header", "HEADER", 1)))
+	if err != nil {
+		t.Fatalf("hashEntry failed: %v", err)
+	}
+	if info1.Hash != info2.Hash || info1.Size != info2.Size {
+		t.Errorf("Expected equal hashes, got %+v and %+v", info1, info2)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// enableVirtualTerminal reports whether the terminal of f interprets ANSI
+// escape sequences, which all terminals outside of Windows do
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}